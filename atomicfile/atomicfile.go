@@ -0,0 +1,49 @@
+// Package atomicfile writes files so that readers never observe partially written contents.
+package atomicfile
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// WriteFile writes data to a temporary file in the same directory as path,
+// flushes it to the disk and renames it to path.
+// If the process is interrupted, the original file is left untouched.
+func WriteFile(path string, data []byte, perm os.FileMode) (err error) {
+	file, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp*")
+	if err != nil {
+		return fmt.Errorf("failed to create a temporary file for %s: %w", path, err)
+	}
+
+	tmp := file.Name()
+
+	defer func() {
+		if err != nil {
+			file.Close()
+			os.Remove(tmp)
+		}
+	}()
+
+	if _, err = file.Write(data); err != nil {
+		return fmt.Errorf("failed to write the temporary file (%s): %w", tmp, err)
+	}
+
+	if err = file.Sync(); err != nil {
+		return fmt.Errorf("failed to flush the temporary file (%s): %w", tmp, err)
+	}
+
+	if err = file.Close(); err != nil {
+		return fmt.Errorf("failed to close the temporary file (%s): %w", tmp, err)
+	}
+
+	if err = os.Chmod(tmp, perm); err != nil {
+		return fmt.Errorf("failed to change the mode of the temporary file (%s): %w", tmp, err)
+	}
+
+	if err = os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to rename the temporary file to %s: %w", path, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,40 @@
+package atomicfile_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sheepla/srss/atomicfile"
+)
+
+func TestWriteFile(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "file.txt")
+
+	for _, content := range []string{"first", "second"} {
+		if err := atomicfile.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatalf("an error occurred on `WriteFile()`: %s", err)
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("failed to read the file: %s", err)
+		}
+
+		if string(data) != content {
+			t.Errorf("got %q, want %q", data, content)
+		}
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("failed to read the directory: %s", err)
+	}
+
+	if len(entries) != 1 {
+		t.Errorf("temporary files are left in the directory: %v", entries)
+	}
+}
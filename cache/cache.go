@@ -2,113 +2,727 @@ package cache
 
 import (
 	"bytes"
+	"encoding/binary"
 	"encoding/gob"
+	"errors"
 	"fmt"
-	"io"
 	"os"
 	"path/filepath"
+	"sort"
+	"time"
 
 	"github.com/kirsle/configdir"
-	"github.com/mmcdole/gofeed"
-	"github.com/pkg/errors"
+	"github.com/sheepla/srss/redact"
+	bolt "go.etcd.io/bbolt"
 )
 
 //nolint:gochecknoglobals
 var (
-	cacheDir  = filepath.Join(configdir.LocalCache(), "srss")
-	cacheFile = filepath.Join(cacheDir, "cache.gob")
+	cacheDir    = filepath.Join(configdir.LocalCache(), "srss")
+	cacheFile   = filepath.Join(cacheDir, "cache.db")
+	legacyFile  = filepath.Join(cacheDir, "cache.gob")
+	openTimeout = 10 * time.Second
 )
 
-func Export(items []*gofeed.Item) (err error) {
+//nolint:gochecknoglobals
+var (
+	bucketItems   = []byte("items")
+	bucketRead    = []byte("read")
+	bucketFeeds   = []byte("feeds")
+	bucketStarred = []byte("starred")
+	// bucketSeen has the keys of the items in the feeds when they were fetched last, which are not new anymore
+	// even if they are deleted from the items, e.g. by pruning the cache.
+	bucketSeen = []byte("seen")
+)
+
+var (
+	// ErrNotFound is returned when the requested entry does not exist in the store.
+	ErrNotFound = errors.New("not found")
+	// ErrLocked is returned when another process keeps the cache locked for writing.
+	ErrLocked = errors.New("the cache is locked by another srss process")
+)
+
+// Store is the storage of the cached items, the read state and the metadata of the feeds.
+//
+// Every write is done in a transaction of the database, so an interrupted process never leaves
+// a truncated cache. A store holds an advisory lock on the file while it is open; a writable store
+// excludes all other processes and a read-only store excludes writers only, so it should be closed
+// as soon as possible.
+type Store interface {
+	// Items returns all items in the order of the feeds with their read and starred state,
+	// followed by the starred items which are no longer in the feeds.
+	Items() ([]*Item, error)
+	// ReplaceItems replaces the items of the feed with the given items and returns the ones which were not in the feed
+	// when it was fetched last. The items deleted since then, e.g. by pruning, are not stored again while they are in the feed.
+	// The full texts of the old items are kept for the same items without them, and the read state of the items
+	// which dropped out of the feed is removed unless they are starred.
+	ReplaceItems(feedURL string, items []*Item) ([]*Item, error)
+	// MarkRead sets the read state of the items identified by Item.Key.
+	MarkRead(read bool, keys ...string) error
+	// Star saves a copy of the item which is kept even after it disappears from the feed.
+	Star(item *Item) error
+	// Unstar removes the saved copy of the item identified by Item.Key.
+	Unstar(key string) error
+	// Feed returns the metadata of the feed, or ErrNotFound.
+	Feed(url string) (*Feed, error)
+	// Feeds returns the metadata of all feeds ordered by Feed.Position.
+	Feeds() ([]*Feed, error)
+	// PutFeed saves the metadata of the feed.
+	PutFeed(feed *Feed) error
+	// DeleteFeed removes the feed, its items and their read state except for the starred ones.
+	DeleteFeed(url string) error
+	// RenameFeed moves the feed, its items and their read and starred state to the new URL,
+	// e.g. when the feed moved permanently. The IDs of the items change with the URL.
+	RenameFeed(oldURL, newURL string) error
+	// DeleteItems removes the items identified by Item.Key and their read state.
+	DeleteItems(keys ...string) error
+	// Clear removes all items, the read state and the metadata of the feeds.
+	// Starred items are kept.
+	Clear() error
+	// Verify checks the integrity of the database and that every entry can be decoded,
+	// and returns the descriptions of the problems found.
+	Verify() ([]string, error)
+	// Close releases the store.
+	Close() error
+}
+
+// Dir returns the cache directory.
+func Dir() string {
+	return cacheDir
+}
+
+// Path returns the path of the cache database.
+func Path() string {
+	return cacheFile
+}
+
+// Open opens the cache database in the cache directory.
+// The cache file of older versions (cache.gob) is imported if the database does not exist yet.
+func Open() (Store, error) {
 	if err := mkdir(cacheDir); err != nil {
-		return fmt.Errorf("failed to create cache parent directory(%s): %w", cacheDir, err)
+		return nil, fmt.Errorf("failed to create cache parent directory(%s): %w", cacheDir, err)
 	}
 
-	file, err := openfileWrite(cacheFile)
+	migrate := !exists(cacheFile) && exists(legacyFile)
+
+	//nolint:exhaustruct,exhaustivestruct
+	store, err := openPath(cacheFile, &bolt.Options{Timeout: openTimeout})
 	if err != nil {
-		return fmt.Errorf("failed to open the cache file(%s): %w", cacheFile, err)
+		return nil, err
 	}
 
-	defer func() {
-		if e := file.Close(); e != nil {
-			err = fmt.Errorf("failed to close the cache file(%s): %w", cacheFile, e)
+	if migrate {
+		if err := importLegacyFile(store, legacyFile); err != nil {
+			// Remove the new database so that the import is tried again by the next run.
+			store.Close()
+			os.Remove(cacheFile)
+
+			return nil, err
 		}
-	}()
+	}
 
-	buf := new(bytes.Buffer)
-	enc := gob.NewEncoder(buf)
+	return store, nil
+}
 
-	if err = enc.Encode(&items); err != nil {
-		return fmt.Errorf("failed to encode cache data: %w", err)
+// OpenReadOnly opens the cache database in the cache directory for reading.
+// Several processes can read the cache at the same time.
+// If the database does not exist yet, it is created with Open.
+func OpenReadOnly() (Store, error) {
+	if !exists(cacheFile) {
+		return Open()
 	}
 
-	fmt.Fprint(file, buf)
+	//nolint:exhaustruct,exhaustivestruct
+	store, err := openPath(cacheFile, &bolt.Options{Timeout: openTimeout, ReadOnly: true})
+	if err != nil {
+		return nil, err
+	}
 
-	return nil
+	return store, nil
 }
 
-//nolint:nonamedreturns
-func Import() (items []*gofeed.Item, err error) {
-	if err := mkdir(cacheDir); err != nil {
-		return nil, fmt.Errorf("failed to create cache parent directory(%s): %w", cacheDir, err)
+// OpenPath opens the cache database at the path.
+// It waits for a while if another process holds the database, then returns ErrLocked.
+func OpenPath(path string) (Store, error) {
+	//nolint:exhaustruct,exhaustivestruct
+	store, err := openPath(path, &bolt.Options{Timeout: openTimeout})
+	if err != nil {
+		return nil, err
 	}
 
-	file, err := openfileRead(cacheFile)
+	return store, nil
+}
+
+func openPath(path string, options *bolt.Options) (*boltStore, error) {
+	//nolint:gomnd
+	db, err := bolt.Open(path, 0o666, options)
 	if err != nil {
-		return nil, fmt.Errorf("failed to open the cache file(%s): %w", cacheFile, err)
+		if errors.Is(err, bolt.ErrTimeout) {
+			return nil, fmt.Errorf("failed to open the cache database(%s): %w", path, ErrLocked)
+		}
+
+		return nil, fmt.Errorf("failed to open the cache database(%s): %w", path, err)
 	}
 
-	defer func() {
-		if e := file.Close(); e != nil {
-			err = fmt.Errorf("failed to close the cache file(%s): %w", cacheFile, e)
+	if options.ReadOnly {
+		var outdated bool
+
+		err := db.View(func(tx *bolt.Tx) error {
+			var err error
+			outdated, err = checkVersion(tx)
+
+			return err
+		})
+		if err != nil {
+			db.Close()
+
+			return nil, fmt.Errorf("failed to open the cache database(%s): %w", path, err)
 		}
-	}()
 
-	err = gob.NewDecoder(file).Decode(&items)
+		// A read-only database cannot be migrated, so open it again for writing.
+		if outdated {
+			db.Close()
+
+			//nolint:exhaustruct,exhaustivestruct
+			return openPath(path, &bolt.Options{Timeout: options.Timeout})
+		}
+
+		return &boltStore{db: db}, nil
+	}
+
+	if err := upgrade(db); err != nil {
+		db.Close()
+
+		return nil, fmt.Errorf("failed to initialize the cache database(%s): %w", path, err)
+	}
+
+	return &boltStore{db: db}, nil
+}
+
+type boltStore struct {
+	db *bolt.DB
+}
+
+func (s *boltStore) Items() ([]*Item, error) {
+	feeds, err := s.Feeds()
 	if err != nil {
-		if errors.Is(err, io.EOF) {
-			return nil, io.EOF
+		return nil, err
+	}
+
+	var items []*Item
+
+	err = s.db.View(func(tx *bolt.Tx) error {
+		read := tx.Bucket(bucketRead)
+		root := tx.Bucket(bucketItems)
+		seen := make(map[string]bool)
+
+		urls := make([]string, 0, len(feeds))
+		for _, feed := range feeds {
+			urls = append(urls, feed.URL)
+		}
+
+		// Items of the feeds without metadata follow the others.
+		err := root.ForEach(func(k, v []byte) error {
+			if _, err := s.feed(tx, string(k)); errors.Is(err, ErrNotFound) {
+				urls = append(urls, string(k))
+			}
+
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("failed to list the feeds: %w", err)
 		}
 
-		return nil, fmt.Errorf("failed to load cache from the file (%s): %w", cacheFile, err)
+		for _, url := range urls {
+			bucket := root.Bucket([]byte(url))
+			if bucket == nil {
+				continue
+			}
+
+			err := bucket.ForEach(func(_, v []byte) error {
+				var item Item
+				if err := decode(v, &item); err != nil {
+					return fmt.Errorf("failed to decode an item of the feed (%s): %w", redact.URL(url), err)
+				}
+
+				item.Read = read.Get([]byte(item.Key())) != nil
+				item.Starred = tx.Bucket(bucketStarred).Get([]byte(item.Key())) != nil
+				seen[item.Key()] = true
+				items = append(items, &item)
+
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		starred, err := starredItems(tx, read, seen)
+		if err != nil {
+			return err
+		}
+
+		items = append(items, starred...)
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load items from the cache: %w", err)
 	}
 
 	return items, nil
 }
 
-func exists(path string) bool {
-	_, err := os.Stat(path)
+func (s *boltStore) ReplaceItems(feedURL string, items []*Item) ([]*Item, error) {
+	var added []*Item
 
-	// return !os.IsNotExist(err)
-	return err == nil
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		root := tx.Bucket(bucketItems)
+
+		fullTexts := make(map[string]string)
+		cached := make(map[string]bool)
+
+		if old := root.Bucket([]byte(feedURL)); old != nil {
+			// The full texts fetched before are kept since the feed does not have them.
+			err := old.ForEach(func(_, data []byte) error {
+				var item Item
+				if err := decode(data, &item); err != nil {
+					return err
+				}
+
+				cached[item.Key()] = true
+
+				if item.FullText != "" {
+					fullTexts[item.Key()] = item.FullText
+				}
+
+				return nil
+			})
+			if err != nil {
+				return fmt.Errorf("failed to read the old items: %w", err)
+			}
+
+			if err := root.DeleteBucket([]byte(feedURL)); err != nil {
+				return fmt.Errorf("failed to delete the old items: %w", err)
+			}
+		}
+
+		bucket, err := root.CreateBucket([]byte(feedURL))
+		if err != nil {
+			return fmt.Errorf("failed to create the bucket of the feed: %w", err)
+		}
+
+		seen := tx.Bucket(bucketSeen)
+
+		known, err := deleteKeys(seen, feedURL)
+		if err != nil {
+			return err
+		}
+
+		legacy := tx.Bucket(bucketLegacy)
+		current := make(map[string]bool, len(items))
+
+		for i, item := range items {
+			current[item.Key()] = true
+
+			if err := seen.Put([]byte(item.Key()), nil); err != nil {
+				return fmt.Errorf("failed to record the item (%s): %w", item.Title, err)
+			}
+
+			// The items deleted from the cache since the last fetch, e.g. by pruning, are not stored again.
+			if known[item.Key()] && !cached[item.Key()] {
+				continue
+			}
+
+			// The items imported from the cache of older versions are not new either.
+			if legacy != nil && legacy.Get([]byte(item.id())) != nil {
+				if err := legacy.Delete([]byte(item.id())); err != nil {
+					return fmt.Errorf("failed to delete the imported item (%s): %w", item.Title, err)
+				}
+			} else if !known[item.Key()] {
+				added = append(added, item)
+			}
+
+			if item.FullText == "" {
+				item.FullText = fullTexts[item.Key()]
+			}
+
+			data, err := encode(item)
+			if err != nil {
+				return fmt.Errorf("failed to encode the item (%s): %w", item.Title, err)
+			}
+
+			// Items are keyed by their position to keep the order of the feed.
+			if err := bucket.Put(itob(i), data); err != nil {
+				return fmt.Errorf("failed to put the item (%s): %w", item.Title, err)
+			}
+		}
+
+		return deleteReadState(tx, feedURL, current)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to save the items of the feed (%s): %w", redact.URL(feedURL), err)
+	}
+
+	return added, nil
 }
 
-func mkdir(path string) error {
-	if !exists(path) {
-		if err := os.MkdirAll(path, os.ModePerm); err != nil {
-			return fmt.Errorf("failed to create directory(%s), %w", path, err)
+func (s *boltStore) MarkRead(read bool, keys ...string) error {
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(bucketRead)
+
+		for _, key := range keys {
+			var err error
+			if read {
+				err = bucket.Put([]byte(key), []byte{1})
+			} else {
+				err = bucket.Delete([]byte(key))
+			}
+
+			if err != nil {
+				return fmt.Errorf("failed to update the read state: %w", err)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save the read state: %w", err)
+	}
+
+	return nil
+}
+
+func (s *boltStore) Feed(url string) (*Feed, error) {
+	var feed *Feed
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		var err error
+		feed, err = s.feed(tx, url)
+
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return feed, nil
+}
+
+func (s *boltStore) feed(tx *bolt.Tx, url string) (*Feed, error) {
+	data := tx.Bucket(bucketFeeds).Get([]byte(url))
+	if data == nil {
+		return nil, fmt.Errorf("feed (%s): %w", redact.URL(url), ErrNotFound)
+	}
+
+	var feed Feed
+	if err := decode(data, &feed); err != nil {
+		return nil, fmt.Errorf("failed to decode the feed (%s): %w", redact.URL(url), err)
+	}
+
+	return &feed, nil
+}
+
+func (s *boltStore) Feeds() ([]*Feed, error) {
+	var feeds []*Feed
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucketFeeds).ForEach(func(k, v []byte) error {
+			var feed Feed
+			if err := decode(v, &feed); err != nil {
+				return fmt.Errorf("failed to decode the feed (%s): %w", redact.URL(string(k)), err)
+			}
+
+			feeds = append(feeds, &feed)
+
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load feeds from the cache: %w", err)
+	}
+
+	sort.SliceStable(feeds, func(i, j int) bool {
+		return feeds[i].Position < feeds[j].Position
+	})
+
+	return feeds, nil
+}
+
+func (s *boltStore) PutFeed(feed *Feed) error {
+	data, err := encode(feed)
+	if err != nil {
+		return fmt.Errorf("failed to encode the feed (%s): %w", redact.URL(feed.URL), err)
+	}
+
+	err = s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucketFeeds).Put([]byte(feed.URL), data)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save the feed (%s): %w", redact.URL(feed.URL), err)
+	}
+
+	return nil
+}
+
+func (s *boltStore) DeleteFeed(url string) error {
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		if err := tx.Bucket(bucketFeeds).Delete([]byte(url)); err != nil {
+			return fmt.Errorf("failed to delete the feed: %w", err)
+		}
+
+		if _, err := deleteKeys(tx.Bucket(bucketSeen), url); err != nil {
+			return err
+		}
+
+		if err := deleteReadState(tx, url, nil); err != nil {
+			return err
 		}
+
+		root := tx.Bucket(bucketItems)
+		if root.Bucket([]byte(url)) == nil {
+			return nil
+		}
+
+		if err := root.DeleteBucket([]byte(url)); err != nil {
+			return fmt.Errorf("failed to delete the items: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete the feed (%s) from the cache: %w", redact.URL(url), err)
 	}
 
 	return nil
 }
 
-func openfileRead(path string) (*os.File, error) {
-	//nolint:gomnd,nosnakecase
-	file, err := os.OpenFile(path, os.O_RDONLY|os.O_CREATE, 0o666)
+func (s *boltStore) RenameFeed(oldURL, newURL string) error {
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		feeds := tx.Bucket(bucketFeeds)
+
+		if feed, err := s.feed(tx, oldURL); err == nil {
+			feed.URL = newURL
+
+			data, err := encode(feed)
+			if err != nil {
+				return err
+			}
+
+			if err := feeds.Put([]byte(newURL), data); err != nil {
+				return fmt.Errorf("failed to put the feed: %w", err)
+			}
+
+			if err := feeds.Delete([]byte(oldURL)); err != nil {
+				return fmt.Errorf("failed to delete the feed: %w", err)
+			}
+		} else if !errors.Is(err, ErrNotFound) {
+			return err
+		}
+
+		if err := renameItems(tx, oldURL, newURL); err != nil {
+			return err
+		}
+
+		// The read state and the starred items are keyed by Item.Key, which starts with the URL of the feed.
+		if err := renameKeys(tx.Bucket(bucketSeen), oldURL, newURL, nil); err != nil {
+			return err
+		}
+
+		if err := renameKeys(tx.Bucket(bucketRead), oldURL, newURL, nil); err != nil {
+			return err
+		}
+
+		return renameKeys(tx.Bucket(bucketStarred), oldURL, newURL, func(data []byte) ([]byte, error) {
+			var item Item
+			if err := decode(data, &item); err != nil {
+				return nil, err
+			}
+
+			item.FeedURL = newURL
+
+			return encode(&item)
+		})
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to open the file(%s): %w", path, err)
+		return fmt.Errorf("failed to rename the feed (%s) in the cache: %w", redact.URL(oldURL), err)
 	}
 
-	return file, nil
+	return nil
 }
 
-func openfileWrite(path string) (*os.File, error) {
-	//nolint:gomnd,nosnakecase
-	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o666)
+// renameItems moves the items of the feed to the bucket of the new URL.
+func renameItems(tx *bolt.Tx, oldURL, newURL string) error {
+	root := tx.Bucket(bucketItems)
+
+	old := root.Bucket([]byte(oldURL))
+	if old == nil {
+		return nil
+	}
+
+	if root.Bucket([]byte(newURL)) != nil {
+		if err := root.DeleteBucket([]byte(newURL)); err != nil {
+			return fmt.Errorf("failed to delete the items: %w", err)
+		}
+	}
+
+	bucket, err := root.CreateBucket([]byte(newURL))
+	if err != nil {
+		return fmt.Errorf("failed to create the bucket of the feed: %w", err)
+	}
+
+	err = old.ForEach(func(k, data []byte) error {
+		var item Item
+		if err := decode(data, &item); err != nil {
+			return err
+		}
+
+		item.FeedURL = newURL
+
+		data, err := encode(&item)
+		if err != nil {
+			return err
+		}
+
+		return bucket.Put(k, data)
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to open the file(%s): %w", path, err)
+		return fmt.Errorf("failed to move the items: %w", err)
+	}
+
+	if err := root.DeleteBucket([]byte(oldURL)); err != nil {
+		return fmt.Errorf("failed to delete the items: %w", err)
+	}
+
+	return nil
+}
+
+// renameKeys replaces the URL of the feed in the keys of the items in the bucket, converting the values with convert if not nil.
+func renameKeys(bucket *bolt.Bucket, oldURL, newURL string, convert func([]byte) ([]byte, error)) error {
+	prefix := []byte(oldURL + "\x00")
+
+	// The keys are collected first since the bucket must not be modified while iterating.
+	var keys [][]byte
+
+	cursor := bucket.Cursor()
+	for k, _ := cursor.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, _ = cursor.Next() {
+		keys = append(keys, append([]byte(nil), k...))
+	}
+
+	for _, key := range keys {
+		value := append([]byte(nil), bucket.Get(key)...)
+
+		if convert != nil {
+			var err error
+			if value, err = convert(value); err != nil {
+				return err
+			}
+		}
+
+		newKey := append([]byte(newURL+"\x00"), key[len(prefix):]...)
+
+		if err := bucket.Put(newKey, value); err != nil {
+			return fmt.Errorf("failed to put the key: %w", err)
+		}
+
+		if err := bucket.Delete(key); err != nil {
+			return fmt.Errorf("failed to delete the key: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// deleteKeys deletes the keys of the items of the feed in the bucket and returns them.
+func deleteKeys(bucket *bolt.Bucket, url string) (map[string]bool, error) {
+	prefix := []byte(url + "\x00")
+	keys := make(map[string]bool)
+
+	cursor := bucket.Cursor()
+	for k, _ := cursor.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, _ = cursor.Next() {
+		keys[string(k)] = true
+	}
+
+	for key := range keys {
+		if err := bucket.Delete([]byte(key)); err != nil {
+			return nil, fmt.Errorf("failed to delete the key: %w", err)
+		}
+	}
+
+	return keys, nil
+}
+
+// deleteReadState deletes the read state of the items of the feed except for the ones in keep and the starred ones,
+// so that the state of the items which dropped out of the feed does not pile up.
+func deleteReadState(tx *bolt.Tx, url string, keep map[string]bool) error {
+	read := tx.Bucket(bucketRead)
+	starred := tx.Bucket(bucketStarred)
+	prefix := []byte(url + "\x00")
+
+	var keys [][]byte
+
+	cursor := read.Cursor()
+	for k, _ := cursor.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, _ = cursor.Next() {
+		if !keep[string(k)] && starred.Get(k) == nil {
+			keys = append(keys, append([]byte(nil), k...))
+		}
+	}
+
+	for _, key := range keys {
+		if err := read.Delete(key); err != nil {
+			return fmt.Errorf("failed to delete the read state: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (s *boltStore) Close() error {
+	if err := s.db.Close(); err != nil {
+		return fmt.Errorf("failed to close the cache database: %w", err)
 	}
 
-	return file, nil
+	return nil
+}
+
+func encode(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(v); err != nil {
+		return nil, fmt.Errorf("failed to encode cache data: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+func decode(data []byte, v interface{}) error {
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(v); err != nil {
+		return fmt.Errorf("failed to decode cache data: %w", err)
+	}
+
+	return nil
+}
+
+func itob(i int) []byte {
+	//nolint:gomnd
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, uint64(i))
+
+	return b
+}
+
+func exists(path string) bool {
+	_, err := os.Stat(path)
+
+	// return !os.IsNotExist(err)
+	return err == nil
+}
+
+func mkdir(path string) error {
+	if !exists(path) {
+		if err := os.MkdirAll(path, os.ModePerm); err != nil {
+			return fmt.Errorf("failed to create directory(%s), %w", path, err)
+		}
+	}
+
+	return nil
 }
@@ -0,0 +1,7 @@
+package cache
+
+// ImportLegacyFile exposes importLegacyFile to the tests.
+func ImportLegacyFile(store Store, path string) error {
+	//nolint:forcetypeassert
+	return importLegacyFile(store.(*boltStore), path)
+}
@@ -0,0 +1,31 @@
+package cache
+
+import (
+	"time"
+
+	"github.com/mmcdole/gofeed"
+	"github.com/sheepla/srss/schedule"
+)
+
+// Feed is the metadata of a subscribed feed.
+type Feed struct {
+	URL       string
+	Title     string
+	Link      string
+	Position  int
+	FetchedAt time.Time
+	// Hints are the intervals and skip hours and days suggested by the feed and the server on the last fetch.
+	Hints schedule.Hints
+}
+
+// NewFeed creates the metadata of the feed fetched from the url.
+// position is the index of the url in the URL entry file.
+func NewFeed(url string, position int, feed *gofeed.Feed) *Feed {
+	return &Feed{
+		URL:       url,
+		Title:     feed.Title,
+		Link:      feed.Link,
+		Position:  position,
+		FetchedAt: time.Now(),
+	}
+}
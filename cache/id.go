@@ -0,0 +1,55 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	idLength        = 8
+	minPrefixLength = 4
+)
+
+// ErrAmbiguousID is returned when an ID prefix matches more than one item.
+var ErrAmbiguousID = errors.New("ambiguous item ID")
+
+// ID returns the short identifier of the item derived from its feed URL and GUID.
+// It does not change across updates, so it can be used to refer to the item from scripts.
+func (item *Item) ID() string {
+	sum := sha256.Sum256([]byte(item.Key()))
+
+	return hex.EncodeToString(sum[:])[:idLength]
+}
+
+// FindByID returns the item whose ID starts with the prefix, like abbreviated commit hashes of git.
+func FindByID(items []*Item, prefix string) (*Item, error) {
+	prefix = strings.ToLower(strings.TrimSpace(prefix))
+	if len(prefix) < minPrefixLength {
+		//nolint:goerr113
+		return nil, fmt.Errorf("item ID (%s) must be at least %d characters", prefix, minPrefixLength)
+	}
+
+	var found *Item
+
+	for _, item := range items {
+		if !strings.HasPrefix(item.ID(), prefix) {
+			continue
+		}
+
+		// The same item may appear twice if it is starred and no longer in the feed.
+		if found != nil && found.Key() != item.Key() {
+			return nil, fmt.Errorf("%w: %s", ErrAmbiguousID, prefix)
+		}
+
+		found = item
+	}
+
+	if found == nil {
+		return nil, fmt.Errorf("item (%s): %w", prefix, ErrNotFound)
+	}
+
+	return found, nil
+}
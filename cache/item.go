@@ -0,0 +1,51 @@
+package cache
+
+import "github.com/mmcdole/gofeed"
+
+// Item is a feed item stored in the cache together with the feed it came from.
+// Read and Starred are filled from the state in the store when the item is loaded.
+type Item struct {
+	*gofeed.Item
+	FeedTitle string
+	FeedURL   string
+	Read      bool
+	Starred   bool
+	// FullText is the HTML of the article extracted from the linked page, for the feeds which only have a summary.
+	FullText string
+}
+
+// Key returns the value which identifies the item across updates.
+func (item *Item) Key() string {
+	return item.FeedURL + "\x00" + item.id()
+}
+
+// id returns the value which identifies the item in its feed.
+func (item *Item) id() string {
+	if item.GUID != "" {
+		return item.GUID
+	}
+
+	if item.Link != "" {
+		return item.Link
+	}
+
+	return item.Title
+}
+
+// NewItems wraps the items of the feed fetched from the url.
+func NewItems(url string, feed *gofeed.Feed) []*Item {
+	items := make([]*Item, 0, len(feed.Items))
+
+	for _, item := range feed.Items {
+		items = append(items, &Item{
+			Item:      item,
+			FeedTitle: feed.Title,
+			FeedURL:   url,
+			Read:      false,
+			Starred:   false,
+			FullText:  "",
+		})
+	}
+
+	return items
+}
@@ -0,0 +1,88 @@
+package cache
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/mmcdole/gofeed"
+	bolt "go.etcd.io/bbolt"
+)
+
+// bucketLegacy has the IDs of the items in the cache.gob file of older versions, see importLegacyFile.
+//
+//nolint:gochecknoglobals
+var bucketLegacy = []byte("legacy")
+
+// importLegacyFile imports the cache.gob file of older versions into the store,
+// then renames the file so that it is not imported again.
+//
+// The file has the items of the last update without their feeds, so they cannot be stored as they are.
+// Their IDs are recorded instead, and the items are associated with their feeds by the next update,
+// which does not report them as new. Older versions had no read state to import.
+func importLegacyFile(store *boltStore, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read the legacy cache file (%s): %w", path, err)
+	}
+
+	items, err := decodeLegacy(data)
+	if err != nil {
+		return fmt.Errorf("failed to load the legacy cache file (%s): %w", path, err)
+	}
+
+	err = store.db.Update(func(tx *bolt.Tx) error {
+		bucket, err := tx.CreateBucketIfNotExists(bucketLegacy)
+		if err != nil {
+			return fmt.Errorf("failed to create the bucket (%s): %w", bucketLegacy, err)
+		}
+
+		for _, item := range items {
+			if item == nil {
+				continue
+			}
+
+			//nolint:exhaustruct,exhaustivestruct
+			if err := bucket.Put([]byte((&Item{Item: item}).id()), nil); err != nil {
+				return fmt.Errorf("failed to record the item (%s): %w", item.Title, err)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to import the legacy cache file (%s): %w", path, err)
+	}
+
+	if err := os.Rename(path, path+".bak"); err != nil {
+		return fmt.Errorf("failed to rename the legacy cache file (%s): %w", path, err)
+	}
+
+	return nil
+}
+
+// decodeLegacy decodes the content of cache.gob, which is a list of the items of all feeds.
+//
+//nolint:nonamedreturns
+func decodeLegacy(data []byte) (items []*gofeed.Item, err error) {
+	// gob panics on some mismatched types instead of returning an error.
+	defer func() {
+		if r := recover(); r != nil {
+			//nolint:goerr113
+			items, err = nil, fmt.Errorf("failed to decode legacy cache data: %v", r)
+		}
+	}()
+
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&items); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("failed to decode legacy cache data: %w", err)
+	}
+
+	return items, nil
+}
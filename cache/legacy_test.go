@@ -0,0 +1,85 @@
+package cache_test
+
+import (
+	"bytes"
+	"encoding/gob"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mmcdole/gofeed"
+	"github.com/sheepla/srss/cache"
+)
+
+// writeLegacyFile writes the items to the path as cache.Export of older versions did.
+func writeLegacyFile(t *testing.T, path string, items []*gofeed.Item) {
+	t.Helper()
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(&items); err != nil {
+		t.Fatalf("failed to encode the items: %s", err)
+	}
+
+	if err := os.WriteFile(path, buf.Bytes(), 0o600); err != nil {
+		t.Fatalf("failed to write the legacy cache file: %s", err)
+	}
+}
+
+//nolint:paralleltest,exhaustruct,exhaustivestruct
+func TestImportLegacyFile(t *testing.T) {
+	store := openStore(t)
+	path := filepath.Join(t.TempDir(), "cache.gob")
+
+	writeLegacyFile(t, path, []*gofeed.Item{{GUID: "1", Title: "1"}, {GUID: "2", Title: "2"}})
+
+	if err := cache.ImportLegacyFile(store, path); err != nil {
+		t.Fatalf("an error occurred on `ImportLegacyFile()`: %s", err)
+	}
+
+	if _, err := os.Stat(path + ".bak"); err != nil {
+		t.Errorf("the legacy cache file is not renamed: %s", err)
+	}
+
+	// The imported items are associated with the feed by the next update, and only the others are new.
+	added, err := store.ReplaceItems("https://example.com/feed.xml", newItems("https://example.com/feed.xml", "1", "2", "3"))
+	if err != nil {
+		t.Fatalf("an error occurred on `ReplaceItems()`: %s", err)
+	}
+
+	if len(added) != 1 || added[0].GUID != "3" {
+		t.Errorf("got %d new items, want only the item 3", len(added))
+	}
+
+	items, err := store.Items()
+	if err != nil {
+		t.Fatalf("an error occurred on `Items()`: %s", err)
+	}
+
+	if len(items) != 3 {
+		t.Errorf("got %d items, want 3", len(items))
+	}
+}
+
+//nolint:paralleltest
+func TestImportLegacyFileInvalid(t *testing.T) {
+	store := openStore(t)
+	path := filepath.Join(t.TempDir(), "cache.gob")
+
+	// A map cannot be decoded as the list of the items.
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(map[string]int{"a": 1}); err != nil {
+		t.Fatalf("failed to encode the data: %s", err)
+	}
+
+	if err := os.WriteFile(path, buf.Bytes(), 0o600); err != nil {
+		t.Fatalf("failed to write the legacy cache file: %s", err)
+	}
+
+	if err := cache.ImportLegacyFile(store, path); err == nil {
+		t.Error("no error for the invalid legacy cache file")
+	}
+
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("the invalid legacy cache file is renamed: %s", err)
+	}
+}
@@ -0,0 +1,164 @@
+package cache
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/sheepla/srss/redact"
+	bolt "go.etcd.io/bbolt"
+)
+
+func (s *boltStore) DeleteItems(keys ...string) error {
+	targets := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		targets[key] = true
+	}
+
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		read := tx.Bucket(bucketRead)
+		for key := range targets {
+			if err := read.Delete([]byte(key)); err != nil {
+				return fmt.Errorf("failed to delete the read state: %w", err)
+			}
+		}
+
+		root := tx.Bucket(bucketItems)
+
+		return root.ForEach(func(url, _ []byte) error {
+			bucket := root.Bucket(url)
+			if bucket == nil {
+				return nil
+			}
+
+			// Collect the positions first since a bucket must not be modified while iterating it.
+			var positions [][]byte
+
+			err := bucket.ForEach(func(k, v []byte) error {
+				var item Item
+				if err := decode(v, &item); err != nil {
+					return fmt.Errorf("failed to decode an item of the feed (%s): %w", redact.URL(string(url)), err)
+				}
+
+				if targets[item.Key()] {
+					positions = append(positions, append([]byte(nil), k...))
+				}
+
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+
+			for _, k := range positions {
+				if err := bucket.Delete(k); err != nil {
+					return fmt.Errorf("failed to delete an item of the feed (%s): %w", redact.URL(string(url)), err)
+				}
+			}
+
+			return nil
+		})
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete items from the cache: %w", err)
+	}
+
+	return nil
+}
+
+func (s *boltStore) Clear() error {
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		if tx.Bucket(bucketLegacy) != nil {
+			if err := tx.DeleteBucket(bucketLegacy); err != nil {
+				return fmt.Errorf("failed to delete the bucket (%s): %w", bucketLegacy, err)
+			}
+		}
+
+		for _, name := range [][]byte{bucketItems, bucketRead, bucketFeeds, bucketSeen} {
+			if err := tx.DeleteBucket(name); err != nil {
+				return fmt.Errorf("failed to delete the bucket (%s): %w", name, err)
+			}
+
+			if _, err := tx.CreateBucket(name); err != nil {
+				return fmt.Errorf("failed to create the bucket (%s): %w", name, err)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to clear the cache: %w", err)
+	}
+
+	return nil
+}
+
+func (s *boltStore) Verify() ([]string, error) {
+	var problems []string
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		for err := range tx.Check() {
+			problems = append(problems, fmt.Sprintf("database: %s", err))
+		}
+
+		err := tx.Bucket(bucketFeeds).ForEach(func(k, v []byte) error {
+			var feed Feed
+			if err := decode(v, &feed); err != nil {
+				problems = append(problems, fmt.Sprintf("feed %s: %s", redact.URL(string(k)), err))
+			}
+
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("failed to verify the feeds: %w", err)
+		}
+
+		err = tx.Bucket(bucketStarred).ForEach(func(k, v []byte) error {
+			var item Item
+			if err := decode(v, &item); err != nil {
+				problems = append(problems, fmt.Sprintf("starred item %q: %s", redactKey(k), err))
+			}
+
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("failed to verify the starred items: %w", err)
+		}
+
+		root := tx.Bucket(bucketItems)
+
+		return root.ForEach(func(url, v []byte) error {
+			bucket := root.Bucket(url)
+			if bucket == nil {
+				problems = append(problems, fmt.Sprintf("feed %s: unexpected value in the items bucket", redact.URL(string(url))))
+
+				return nil
+			}
+
+			return bucket.ForEach(func(k, v []byte) error {
+				var item Item
+				if err := decode(v, &item); err != nil {
+					problems = append(problems, fmt.Sprintf("feed %s: item #%x: %s", redact.URL(string(url)), k, err))
+				} else if item.Item == nil {
+					problems = append(problems, fmt.Sprintf("feed %s: item #%x: empty item", redact.URL(string(url)), k))
+				}
+
+				return nil
+			})
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to verify the cache: %w", err)
+	}
+
+	return problems, nil
+}
+
+// redactKey masks the credentials in the URL of the feed in the key of an item, see Item.Key.
+func redactKey(key []byte) string {
+	url, id, found := bytes.Cut(key, []byte("\x00"))
+	if !found {
+		return string(key)
+	}
+
+	return redact.URL(string(url)) + "\x00" + string(id)
+}
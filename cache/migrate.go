@@ -0,0 +1,157 @@
+package cache
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// ErrNewerVersion is returned when the cache was written by a newer version of srss.
+var ErrNewerVersion = errors.New("the cache is from a newer version of srss")
+
+//nolint:gochecknoglobals
+var (
+	bucketMeta = []byte("meta")
+	keyVersion = []byte("version")
+)
+
+// migration upgrades the cache to the version.
+type migration struct {
+	version     uint64
+	description string
+	migrate     func(tx *bolt.Tx) error
+}
+
+// migrations are applied in order to the caches older than their version.
+// Append a new migration here when changing what is stored in the cache,
+// and never modify the existing ones.
+//
+//nolint:gochecknoglobals
+var migrations = []migration{
+	{
+		version:     1,
+		description: "create the buckets of the items, the read state and the feeds",
+		migrate: func(tx *bolt.Tx) error {
+			for _, name := range [][]byte{bucketItems, bucketRead, bucketFeeds} {
+				if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+					return fmt.Errorf("failed to create the bucket (%s): %w", name, err)
+				}
+			}
+
+			return nil
+		},
+	},
+	{
+		version:     2,
+		description: "create the bucket of the starred items",
+		migrate: func(tx *bolt.Tx) error {
+			if _, err := tx.CreateBucketIfNotExists(bucketStarred); err != nil {
+				return fmt.Errorf("failed to create the bucket (%s): %w", bucketStarred, err)
+			}
+
+			return nil
+		},
+	},
+	{
+		version:     3,
+		description: "record the items seen in the feeds to tell the new ones",
+		migrate: func(tx *bolt.Tx) error {
+			seen, err := tx.CreateBucketIfNotExists(bucketSeen)
+			if err != nil {
+				return fmt.Errorf("failed to create the bucket (%s): %w", bucketSeen, err)
+			}
+
+			// The cached items are not new on the next update.
+			root := tx.Bucket(bucketItems)
+
+			return root.ForEach(func(url, _ []byte) error {
+				bucket := root.Bucket(url)
+				if bucket == nil {
+					return nil
+				}
+
+				return bucket.ForEach(func(_, v []byte) error {
+					var item Item
+					if err := decode(v, &item); err != nil {
+						return err
+					}
+
+					return seen.Put([]byte(item.Key()), nil)
+				})
+			})
+		},
+	},
+}
+
+// formatVersion returns the version of the cache format written by this version of srss.
+func formatVersion() uint64 {
+	return migrations[len(migrations)-1].version
+}
+
+// readVersion returns the format version of the cache.
+// Caches created before the version was introduced are version 0.
+func readVersion(tx *bolt.Tx) uint64 {
+	bucket := tx.Bucket(bucketMeta)
+	if bucket == nil {
+		return 0
+	}
+
+	data := bucket.Get(keyVersion)
+	//nolint:gomnd
+	if len(data) != 8 {
+		return 0
+	}
+
+	return binary.BigEndian.Uint64(data)
+}
+
+// checkVersion returns ErrNewerVersion if the cache cannot be read by this version of srss,
+// and reports whether the cache needs to be upgraded.
+func checkVersion(tx *bolt.Tx) (bool, error) {
+	version := readVersion(tx)
+	if version > formatVersion() {
+		return false, fmt.Errorf("%w (cache format version %d, supported up to %d)", ErrNewerVersion, version, formatVersion())
+	}
+
+	return version < formatVersion(), nil
+}
+
+// upgrade applies the pending migrations and records the new version in the same transaction,
+// so a failed migration leaves the cache as it was.
+func upgrade(db *bolt.DB) error {
+	err := db.Update(func(tx *bolt.Tx) error {
+		if _, err := checkVersion(tx); err != nil {
+			return err
+		}
+
+		version := readVersion(tx)
+
+		for _, m := range migrations {
+			if m.version <= version {
+				continue
+			}
+
+			if err := m.migrate(tx); err != nil {
+				return fmt.Errorf("failed to migrate the cache to version %d (%s): %w", m.version, m.description, err)
+			}
+		}
+
+		bucket, err := tx.CreateBucketIfNotExists(bucketMeta)
+		if err != nil {
+			return fmt.Errorf("failed to create the bucket (%s): %w", bucketMeta, err)
+		}
+
+		if err := bucket.Put(keyVersion, itob(int(formatVersion()))); err != nil {
+			return fmt.Errorf("failed to save the cache format version: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to upgrade the cache: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,62 @@
+package cache_test
+
+import (
+	"encoding/binary"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/sheepla/srss/cache"
+	bolt "go.etcd.io/bbolt"
+)
+
+func writeVersion(t *testing.T, path string, version uint64) {
+	t.Helper()
+
+	db, err := bolt.Open(path, 0o600, nil)
+	if err != nil {
+		t.Fatalf("failed to open the database: %s", err)
+	}
+	defer db.Close()
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		bucket, err := tx.CreateBucketIfNotExists([]byte("meta"))
+		if err != nil {
+			return err
+		}
+
+		data := make([]byte, 8)
+		binary.BigEndian.PutUint64(data, version)
+
+		return bucket.Put([]byte("version"), data)
+	})
+	if err != nil {
+		t.Fatalf("failed to write the version: %s", err)
+	}
+}
+
+//nolint:paralleltest
+func TestOpenNewerVersion(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.db")
+	writeVersion(t, path, 999)
+
+	if _, err := cache.OpenPath(path); !errors.Is(err, cache.ErrNewerVersion) {
+		t.Errorf("got %v, want ErrNewerVersion", err)
+	}
+}
+
+//nolint:paralleltest
+func TestOpenUnversioned(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.db")
+	writeVersion(t, path, 0)
+
+	store, err := cache.OpenPath(path)
+	if err != nil {
+		t.Fatalf("an error occurred on `OpenPath()`: %s", err)
+	}
+	defer store.Close()
+
+	if _, err := store.Items(); err != nil {
+		t.Errorf("an error occurred on `Items()` of the migrated cache: %s", err)
+	}
+}
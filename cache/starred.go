@@ -0,0 +1,72 @@
+package cache
+
+import (
+	"fmt"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+func (s *boltStore) Star(item *Item) error {
+	data, err := encode(item)
+	if err != nil {
+		return fmt.Errorf("failed to encode the item (%s): %w", item.Title, err)
+	}
+
+	err = s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucketStarred).Put([]byte(item.Key()), data)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to star the item (%s): %w", item.Title, err)
+	}
+
+	item.Starred = true
+
+	return nil
+}
+
+func (s *boltStore) Unstar(key string) error {
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		if err := tx.Bucket(bucketStarred).Delete([]byte(key)); err != nil {
+			return err
+		}
+
+		// The read state is not needed anymore if the item is no longer in the feed.
+		if tx.Bucket(bucketSeen).Get([]byte(key)) == nil {
+			return tx.Bucket(bucketRead).Delete([]byte(key))
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to unstar the item: %w", err)
+	}
+
+	return nil
+}
+
+// starredItems returns the starred items except for the ones in seen.
+func starredItems(tx *bolt.Tx, read *bolt.Bucket, seen map[string]bool) ([]*Item, error) {
+	var items []*Item
+
+	err := tx.Bucket(bucketStarred).ForEach(func(k, v []byte) error {
+		if seen[string(k)] {
+			return nil
+		}
+
+		var item Item
+		if err := decode(v, &item); err != nil {
+			return fmt.Errorf("failed to decode a starred item: %w", err)
+		}
+
+		item.Read = read.Get(k) != nil
+		item.Starred = true
+		items = append(items, &item)
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load the starred items: %w", err)
+	}
+
+	return items, nil
+}
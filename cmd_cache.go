@@ -0,0 +1,342 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/sheepla/srss/cache"
+	"github.com/sheepla/srss/filter"
+	"github.com/sheepla/srss/redact"
+	"github.com/sheepla/srss/search"
+	"github.com/sheepla/srss/ui"
+	"github.com/urfave/cli/v2"
+)
+
+//nolint:exhaustruct,exhaustivestruct,funlen
+func cacheCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "cache",
+		Usage: "Inspect or manage the cache",
+		Subcommands: []*cli.Command{
+			{
+				Name:   "stats",
+				Usage:  "Show the number of items per feed and the size of the cache",
+				Action: runCacheStatsCommand,
+			},
+			{
+				Name:  "prune",
+				Usage: "Remove old items from the cache",
+				Description: "Items still present in the feed are not fetched again until they drop out of it, " +
+					"so they do not come back as new items",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:     "older-than",
+						Usage:    "Remove items published before the duration ago (e.g. 90d, 12w, 1y) or the date (e.g. 2022-08-01)",
+						Required: true,
+					},
+					&cli.BoolFlag{
+						Name:  "keep-starred",
+						Usage: "Keep starred items regardless of their age, set false to unstar and remove them",
+						Value: true,
+					},
+					&cli.BoolFlag{
+						Name:    "dry-run",
+						Aliases: []string{"n"},
+						Usage:   "Print the items to remove without removing them",
+					},
+				},
+				Action: runCachePruneCommand,
+			},
+			{
+				Name:  "clear",
+				Usage: "Remove all items except for the starred ones and the read state from the cache",
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:    "yes",
+						Aliases: []string{"y"},
+						Usage:   "Do not ask for confirmation",
+					},
+				},
+				Action: runCacheClearCommand,
+			},
+			{
+				Name:   "verify",
+				Usage:  "Check that the cache can be decoded and report corrupt entries",
+				Action: runCacheVerifyCommand,
+			},
+		},
+	}
+}
+
+func runCacheStatsCommand(ctx *cli.Context) error {
+	items, err := loadItems()
+	if err != nil {
+		return err
+	}
+
+	type stats struct {
+		title          string
+		items, unread  int
+		oldest, newest *time.Time
+	}
+
+	var (
+		urls   []string
+		byFeed = make(map[string]*stats)
+		total  stats
+	)
+
+	count := func(s *stats, item *cache.Item) {
+		s.items++
+
+		if !item.Read {
+			s.unread++
+		}
+
+		if date := filter.Date(item); date != nil {
+			if s.oldest == nil || date.Before(*s.oldest) {
+				s.oldest = date
+			}
+
+			if s.newest == nil || date.After(*s.newest) {
+				s.newest = date
+			}
+		}
+	}
+
+	for _, item := range items {
+		s, ok := byFeed[item.FeedURL]
+		if !ok {
+			//nolint:exhaustruct,exhaustivestruct
+			s = &stats{title: item.FeedTitle}
+			byFeed[item.FeedURL] = s
+			urls = append(urls, item.FeedURL)
+		}
+
+		count(s, item)
+		count(&total, item)
+	}
+
+	formatDate := func(t *time.Time) string {
+		if t == nil {
+			return "-"
+		}
+
+		return t.Local().Format("2006-01-02")
+	}
+
+	//nolint:gomnd
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(w, "FEED\tITEMS\tUNREAD\tOLDEST\tNEWEST")
+
+	for _, url := range urls {
+		s := byFeed[url]
+		title := s.title
+
+		if title == "" {
+			title = redact.URL(url)
+		}
+
+		fmt.Fprintf(w, "%s\t%d\t%d\t%s\t%s\n", title, s.items, s.unread, formatDate(s.oldest), formatDate(s.newest))
+	}
+
+	fmt.Fprintf(w, "TOTAL\t%d\t%d\t%s\t%s\n", total.items, total.unread, formatDate(total.oldest), formatDate(total.newest))
+
+	if err := w.Flush(); err != nil {
+		return cli.Exit(fmt.Sprintf("failed to print the stats: %s", err), int(exitCodeErrOutput))
+	}
+
+	//nolint:forbidigo
+	fmt.Println()
+
+	for _, path := range []string{cache.Path(), search.Path()} {
+		size := "-"
+		if info, err := os.Stat(path); err == nil {
+			size = ui.FormatBytes(info.Size())
+		}
+
+		//nolint:forbidigo
+		fmt.Printf("%s: %s\n", path, size)
+	}
+
+	return nil
+}
+
+func runCachePruneCommand(ctx *cli.Context) error {
+	before, err := filter.ParseSince(strings.TrimSpace(ctx.String("older-than")), time.Now())
+	if err != nil {
+		return cli.Exit(
+			fmt.Sprintf("invalid value of --older-than: %s", err),
+			int(exitCodeErrArgs),
+		)
+	}
+
+	items, err := loadItems()
+	if err != nil {
+		return err
+	}
+
+	var (
+		keys    []string
+		starred []*cache.Item
+	)
+
+	for _, item := range items {
+		if item.Starred && ctx.Bool("keep-starred") {
+			continue
+		}
+
+		// Items without any date cannot be judged, so they are kept.
+		if date := filter.Date(item); date != nil && date.Before(before) {
+			keys = append(keys, item.Key())
+
+			if item.Starred {
+				starred = append(starred, item)
+			}
+
+			if ctx.Bool("dry-run") {
+				//nolint:forbidigo
+				fmt.Printf("%s\t%s\n", date.Local().Format("2006-01-02"), item.Title)
+			}
+		}
+	}
+
+	if ctx.Bool("dry-run") || len(keys) == 0 {
+		//nolint:forbidigo
+		fmt.Printf("%d items to remove\n", len(keys))
+
+		return nil
+	}
+
+	err = withStore(func(store cache.Store) error {
+		if err := store.DeleteItems(keys...); err != nil {
+			return err
+		}
+
+		for _, item := range starred {
+			if err := store.Unstar(item.Key()); err != nil {
+				return err
+			}
+		}
+
+		remaining, err := store.Items()
+		if err != nil {
+			return err
+		}
+
+		return search.Build(remaining).Save()
+	})
+	if err != nil {
+		return cli.Exit(
+			fmt.Sprintf("failed to prune the cache: %s", err),
+			int(exitCodeErrCache),
+		)
+	}
+
+	//nolint:forbidigo
+	fmt.Printf("Removed %d items\n", len(keys))
+
+	return nil
+}
+
+func runCacheClearCommand(ctx *cli.Context) error {
+	if !ctx.Bool("yes") && !confirm(fmt.Sprintf("Remove all items and the read state in %s?", cache.Path())) {
+		return cli.Exit("canceled", int(exitCodeOK))
+	}
+
+	err := withStore(func(store cache.Store) error {
+		return store.Clear()
+	})
+	if err != nil {
+		return cli.Exit(
+			fmt.Sprintf("failed to clear the cache: %s", err),
+			int(exitCodeErrCache),
+		)
+	}
+
+	if err := os.Remove(search.Path()); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return cli.Exit(
+			fmt.Sprintf("failed to remove the search index: %s", err),
+			int(exitCodeErrCache),
+		)
+	}
+
+	return nil
+}
+
+func runCacheVerifyCommand(ctx *cli.Context) error {
+	store, err := cache.OpenReadOnly()
+	if err != nil {
+		return cli.Exit(
+			fmt.Sprintf("failed to open cache: %s", err),
+			int(exitCodeErrCache),
+		)
+	}
+	defer store.Close()
+
+	problems, err := store.Verify()
+	if err != nil {
+		return cli.Exit(
+			fmt.Sprintf("failed to verify cache: %s", err),
+			int(exitCodeErrCache),
+		)
+	}
+
+	if _, err := search.Load(); err != nil && !errors.Is(err, os.ErrNotExist) {
+		problems = append(problems, fmt.Sprintf("search index: %s (run `srss update` to rebuild it)", err))
+	}
+
+	for _, problem := range problems {
+		fmt.Fprintln(os.Stderr, problem)
+	}
+
+	if len(problems) != 0 {
+		return cli.Exit(
+			fmt.Sprintf("found %d problems in the cache", len(problems)),
+			int(exitCodeErrCache),
+		)
+	}
+
+	//nolint:forbidigo
+	fmt.Println("OK")
+
+	return nil
+}
+
+// withStore opens the cache for writing and calls f with it.
+//
+//nolint:nonamedreturns
+func withStore(f func(store cache.Store) error) (err error) {
+	store, err := cache.Open()
+	if err != nil {
+		return fmt.Errorf("failed to open the cache: %w", err)
+	}
+
+	defer func() {
+		if e := store.Close(); e != nil && err == nil {
+			err = e
+		}
+	}()
+
+	return f(store)
+}
+
+// confirm asks the user a yes/no question on the terminal.
+func confirm(question string) bool {
+	fmt.Fprintf(os.Stderr, "%s [y/N] ", question)
+
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil {
+		return false
+	}
+
+	answer = strings.ToLower(strings.TrimSpace(answer))
+
+	return answer == "y" || answer == "yes"
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/sheepla/srss/cache"
+	"github.com/sheepla/srss/config"
+	"github.com/sheepla/srss/filter"
+	"github.com/sheepla/srss/output"
+	"github.com/urfave/cli/v2"
+)
+
+//nolint:exhaustruct,exhaustivestruct
+func catFlags() []cli.Flag {
+	flags := append(queryFlags(),
+		&cli.StringFlag{
+			Name:    "feed",
+			Aliases: []string{"f"},
+			Usage:   "Print only items of the feeds whose title or URL contains the string",
+		},
+		&cli.StringFlag{
+			Name:    "since",
+			Aliases: []string{"s"},
+			Usage:   "Print only items published since the duration ago (e.g. 12h, 7d, 2w) or the date (e.g. 2022-08-01)",
+		},
+		&cli.BoolFlag{
+			Name:    "unread",
+			Aliases: []string{"u"},
+			Usage:   "Print only unread items",
+		},
+		&cli.BoolFlag{
+			Name:  "starred",
+			Usage: "Print only starred items",
+		},
+		&cli.StringSliceFlag{
+			Name:    "tag",
+			Aliases: []string{"t"},
+			Usage:   "Print only items which have the category (can be specified multiple times)",
+		},
+	)
+
+	return append(flags, outputFlags()...)
+}
+
+//nolint:exhaustruct,exhaustivestruct
+func outputFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.IntFlag{
+			Name:    "limit",
+			Aliases: []string{"n"},
+			Usage:   "Print at most the number of items (0 means no limit)",
+		},
+		&cli.StringFlag{
+			Name:    "format",
+			Aliases: []string{"o"},
+			Usage:   fmt.Sprintf("Output format (%s)", strings.Join(output.Formats(), ", ")),
+			Value:   string(output.FormatTable),
+		},
+		&cli.StringFlag{
+			Name:    "template",
+			Aliases: []string{"T"},
+			Usage:   "Go template applied to each item (e.g. '{{.Title}} {{.Link}}'), overrides --format",
+		},
+	}
+}
+
+func runCatCommand(ctx *cli.Context) error {
+	items, err := loadItems()
+	if err != nil {
+		return err
+	}
+
+	//nolint:exhaustruct,exhaustivestruct
+	f := &filter.Filter{
+		Feed:    strings.TrimSpace(ctx.String("feed")),
+		Unread:  ctx.Bool("unread"),
+		Starred: ctx.Bool("starred"),
+		Tags:    ctx.StringSlice("tag"),
+		Text:    strings.Join(ctx.Args().Slice(), " "),
+	}
+
+	if since := strings.TrimSpace(ctx.String("since")); since != "" {
+		f.Since, err = filter.ParseSince(since, time.Now())
+		if err != nil {
+			return cli.Exit(
+				fmt.Sprintf("invalid value of --since: %s", err),
+				int(exitCodeErrArgs),
+			)
+		}
+	}
+
+	conf, err := config.Load()
+	if err != nil {
+		return cli.Exit(
+			fmt.Sprintf("failed to load config: %s", err),
+			int(exitCodeErrConfig),
+		)
+	}
+
+	query, err := queryFromFlags(ctx, conf)
+	if err != nil {
+		return err
+	}
+
+	items = filter.Apply(items, f)
+	if query != nil {
+		items = filter.Apply(items, query)
+	}
+
+	return writeItems(ctx, items)
+}
+
+// writeItems prints the items according to the flags returned by outputFlags.
+func writeItems(ctx *cli.Context, items []*cache.Item) error {
+	if limit := ctx.Int("limit"); limit > 0 && limit < len(items) {
+		items = items[:limit]
+	}
+
+	var err error
+
+	if tmpl := ctx.String("template"); tmpl != "" {
+		err = output.WriteTemplate(os.Stdout, items, tmpl)
+	} else {
+		err = output.Write(os.Stdout, items, output.Format(ctx.String("format")))
+	}
+
+	if err != nil {
+		return cli.Exit(
+			fmt.Sprintf("failed to print the items: %s", err),
+			int(exitCodeErrOutput),
+		)
+	}
+
+	return nil
+}
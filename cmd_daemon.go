@@ -0,0 +1,312 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math/rand"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/sheepla/srss/cache"
+	"github.com/sheepla/srss/config"
+	"github.com/sheepla/srss/fetch"
+	"github.com/sheepla/srss/filter"
+	"github.com/sheepla/srss/redact"
+	"github.com/sheepla/srss/schedule"
+	"github.com/sheepla/srss/urlentry"
+	"github.com/urfave/cli/v2"
+)
+
+const (
+	defaultInterval = 30 * time.Minute
+	// daemonPollInterval is the longest sleep of the daemon so that changes of the URL entry file are noticed.
+	daemonPollInterval = time.Minute
+)
+
+//nolint:exhaustruct,exhaustivestruct
+func daemonCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "daemon",
+		Usage: "Keep refreshing the feeds in the background on a schedule",
+		Description: "Each feed is fetched at the interval in the config file (30m by default) " +
+			"or the longer one suggested by the feed (<ttl>, sy:updatePeriod) or the server (Cache-Control: max-age), " +
+			"and not in the <skipHours> and <skipDays> of the feed. Failing feeds are retried with an exponential backoff",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    "interval",
+				Aliases: []string{"i"},
+				Usage:   "Default interval to fetch each feed, overriding the config file (e.g. 15m, 2h)",
+			},
+		},
+		Action: runDaemonCommand,
+	}
+}
+
+// feedSchedule is the state of a feed in the daemon.
+type feedSchedule struct {
+	next     time.Time
+	failures int
+}
+
+// daemon refreshes the feeds in the URL entry file when they are due.
+type daemon struct {
+	interval  string
+	schedules map[string]*feedSchedule
+	rnd       *rand.Rand
+	logger    *log.Logger
+}
+
+func runDaemonCommand(ctx *cli.Context) error {
+	if ctx.NArg() != 0 {
+		return cli.Exit(
+			fmt.Sprintf("extra arguments (%s)", ctx.Args().Slice()),
+			int(exitCodeErrArgs),
+		)
+	}
+
+	if interval := ctx.String("interval"); interval != "" {
+		if _, err := filter.ParseDuration(interval); err != nil {
+			return cli.Exit(
+				fmt.Sprintf("invalid value of --interval: %s", err),
+				int(exitCodeErrArgs),
+			)
+		}
+	}
+
+	sigctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	d := &daemon{
+		interval:  ctx.String("interval"),
+		schedules: make(map[string]*feedSchedule),
+		//nolint:gosec
+		rnd:    rand.New(rand.NewSource(time.Now().UnixNano())),
+		logger: log.New(os.Stderr, "", log.LstdFlags),
+	}
+
+	d.logger.Printf("started, writing to %s", cache.Path())
+
+	for {
+		wait := d.refresh(sigctx, time.Now())
+
+		select {
+		case <-sigctx.Done():
+			d.logger.Printf("stopped")
+
+			return cli.Exit("", int(exitCodeOK))
+		case <-time.After(wait):
+		}
+	}
+}
+
+// refresh fetches the feeds which are due and returns how long to wait for the next refresh.
+// Errors are logged so that the daemon keeps running. The fetches, downloads and hooks stop when the context is done.
+func (d *daemon) refresh(ctx context.Context, now time.Time) time.Duration {
+	conf, err := config.Load()
+	if err != nil {
+		d.logger.Printf("failed to load config: %s", err)
+
+		return daemonPollInterval
+	}
+
+	urls, err := urlentry.Load()
+	if err != nil {
+		d.logger.Printf("failed to load URL entry: %s", err)
+
+		return daemonPollInterval
+	}
+
+	for url := range d.schedules {
+		if !contains(urls, url) {
+			delete(d.schedules, url)
+		}
+	}
+
+	fetchers, err := newFeedFetchers(conf)
+	if err != nil {
+		d.logger.Printf("%s", err)
+
+		return daemonPollInterval
+	}
+
+	fetchCtx, cancel, err := withFetchDeadline(ctx, &conf.Fetch, "")
+	if err != nil {
+		d.logger.Printf("%s", err)
+
+		return daemonPollInterval
+	}
+	defer cancel()
+
+	// The new feeds are scheduled by the last fetch in the cache, e.g. by update or the daemon running before.
+	var feeds map[string]*cache.Feed
+
+	results := make([]*fetch.Result, 0, len(urls))
+
+	var gone []string
+
+	for _, url := range urls {
+		// The feeds fetched before the daemon is stopped are still saved.
+		if ctx.Err() != nil {
+			break
+		}
+
+		sched, ok := d.schedules[url]
+		if !ok {
+			if feeds == nil {
+				if feeds, err = loadFeeds(); err != nil {
+					d.logger.Printf("%s", err)
+
+					return daemonPollInterval
+				}
+			}
+
+			sched = &feedSchedule{next: nextFetch(feeds[url], d.feedInterval(conf, url)), failures: 0}
+			d.schedules[url] = sched
+		}
+
+		if sched.next.After(now) {
+			continue
+		}
+
+		result, err := fetchFeed(fetchCtx, fetchers, url)
+		if isGone(err) {
+			gone = append(gone, url)
+
+			continue
+		}
+
+		if err != nil && ctx.Err() != nil {
+			// The feed is fetched again when the daemon starts next time.
+			break
+		}
+
+		if err != nil {
+			sched.failures++
+			delay := schedule.Backoff(sched.failures, d.rnd)
+			sched.next = now.Add(delay)
+			d.logger.Printf("%s, retrying in %s", err, delay.Round(time.Second))
+
+			continue
+		}
+
+		sched.failures = 0
+		sched.next = schedule.Next(now, schedule.Jitter(d.feedInterval(conf, url), d.rnd), result.Hints)
+		d.logger.Printf("fetched %s (%d items), next at %s", redact.URL(url), len(result.Feed.Items), sched.next.Format("15:04:05"))
+
+		results = append(results, result)
+	}
+
+	if len(results) == 0 && len(gone) == 0 {
+		return d.nextWait(time.Now())
+	}
+
+	fetchedURLs := make([]string, len(results))
+	for i, result := range results {
+		fetchedURLs[i] = result.URL
+	}
+
+	urls, changes, err := applyFeedChanges(conf, urls, results, gone)
+	if err != nil {
+		d.logger.Printf("%s", err)
+
+		return daemonPollInterval
+	}
+
+	for _, change := range changes {
+		d.logger.Print(change)
+	}
+
+	// The schedules follow the feeds which moved.
+	for i, result := range results {
+		if result.URL != fetchedURLs[i] {
+			d.schedules[result.URL] = d.schedules[fetchedURLs[i]]
+			delete(d.schedules, fetchedURLs[i])
+		}
+	}
+
+	for _, url := range gone {
+		delete(d.schedules, url)
+	}
+
+	if len(results) != 0 {
+		// The hooks are run only for the feeds fetched before.
+		var cached map[string]*cache.Feed
+		if len(conf.Hooks) != 0 {
+			if cached, err = loadFeeds(); err != nil {
+				d.logger.Printf("%s", err)
+			}
+		}
+
+		items, added, err := refreshCache(ctx, fetchers, urls, results, logWriter{d.logger}, logWriter{d.logger})
+		if err != nil {
+			// The feeds are fetched again soon since the items are not saved, e.g. while the cache is locked.
+			d.logger.Printf("failed to update the cache: %s", err)
+
+			for _, result := range results {
+				d.schedules[result.URL].next = now.Add(daemonPollInterval)
+			}
+		} else {
+			for i, result := range results {
+				if len(added[i]) != 0 {
+					d.logger.Printf("%d new items in %s", len(added[i]), redact.URL(result.URL))
+				}
+			}
+
+			for _, err := range runHooks(ctx, conf, results, added, cached, logWriter{d.logger}) {
+				d.logger.Printf("failed to run the hook: %s", err)
+			}
+
+			if err := autoDownload(ctx, items, false, logWriter{d.logger}, logWriter{d.logger}); err != nil && err.Error() != "" {
+				// The failed downloads are already reported, and are retried with the next refresh.
+				d.logger.Printf("failed to download the enclosures: %s", err)
+			}
+		}
+	}
+
+	return d.nextWait(time.Now())
+}
+
+// logWriter writes to the logger so that all output of the daemon has the time and goes to the same stream.
+type logWriter struct {
+	logger *log.Logger
+}
+
+func (w logWriter) Write(p []byte) (int, error) {
+	w.logger.Print(string(p))
+
+	return len(p), nil
+}
+
+// feedInterval returns the interval of the feed in the config or the flag, falling back to the default.
+func (d *daemon) feedInterval(conf *config.Config, url string) time.Duration {
+	interval, err := feedInterval(conf, url, d.interval, defaultInterval)
+	if err != nil {
+		d.logger.Printf("%s, using %s", err, defaultInterval)
+	}
+
+	if interval <= 0 {
+		return defaultInterval
+	}
+
+	return interval
+}
+
+// nextWait returns the duration until the earliest due feed, at most daemonPollInterval.
+func (d *daemon) nextWait(now time.Time) time.Duration {
+	wait := daemonPollInterval
+
+	for _, sched := range d.schedules {
+		if until := sched.next.Sub(now); until < wait {
+			wait = until
+		}
+	}
+
+	if wait < 0 {
+		return 0
+	}
+
+	return wait
+}
@@ -0,0 +1,435 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sheepla/srss/cache"
+	"github.com/sheepla/srss/config"
+	"github.com/sheepla/srss/download"
+	"github.com/sheepla/srss/filter"
+	"github.com/sheepla/srss/ui"
+	"github.com/urfave/cli/v2"
+)
+
+const defaultDownloadConcurrency = 3
+
+//nolint:exhaustruct,exhaustivestruct
+func downloadCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "download",
+		Aliases:   []string{"dl"},
+		Usage:     "Download the enclosures of the items, e.g. podcast episodes",
+		ArgsUsage: "[id...]",
+		Description: "Without IDs, --feed, --query or --auto, the items are selected in the fuzzyfinder. " +
+			"Files which already exist are skipped and interrupted downloads are resumed",
+		Flags: append(append([]cli.Flag{
+			&cli.StringSliceFlag{
+				Name:    "feed",
+				Aliases: []string{"f"},
+				Usage:   "Download all items of the feeds whose title or URL contains the value",
+			},
+			&cli.BoolFlag{
+				Name:    "auto",
+				Aliases: []string{"a"},
+				Usage:   "Download the items selected by the rules in the config file",
+			},
+			&cli.StringFlag{
+				Name:    "dir",
+				Aliases: []string{"d"},
+				Usage:   "Directory to save the files (default: ~/Podcasts)",
+			},
+			&cli.StringFlag{
+				Name:    "layout",
+				Aliases: []string{"l"},
+				Usage:   "Path of the files relative to the directory with {feed}, {date}, {title}, {id} and {ext}",
+			},
+			&cli.IntFlag{
+				Name:    "concurrency",
+				Aliases: []string{"j"},
+				Usage:   "Number of files downloaded at a time (default: 3)",
+			},
+			&cli.BoolFlag{
+				Name:    "dry-run",
+				Aliases: []string{"n"},
+				Usage:   "Print the URLs and paths to download without downloading them",
+			},
+		}, queryFlags()...), viewFlags()...),
+		Action: runDownloadCommand,
+	}
+}
+
+// downloadSettings is the download config merged with the flags.
+type downloadSettings struct {
+	dir         string
+	layout      string
+	concurrency int
+}
+
+func runDownloadCommand(ctx *cli.Context) error {
+	conf, err := config.Load()
+	if err != nil {
+		return cli.Exit(
+			fmt.Sprintf("failed to load config: %s", err),
+			int(exitCodeErrConfig),
+		)
+	}
+
+	settings, err := downloadSettingsFromFlags(ctx, &conf.Download)
+	if err != nil {
+		return err
+	}
+
+	items, err := loadItems()
+	if err != nil {
+		return err
+	}
+
+	items, err = selectDownloadItems(ctx, conf, filter.Apply(items, hasEnclosures{}))
+	if err != nil {
+		return err
+	}
+
+	jobs := download.Plan(items, settings.dir, settings.layout)
+	if len(jobs) == 0 {
+		return cli.Exit("No enclosures to download", int(exitCodeOK))
+	}
+
+	if ctx.Bool("dry-run") {
+		for _, job := range jobs {
+			//nolint:forbidigo
+			fmt.Printf("%s\t%s\n", job.URL, job.Path)
+		}
+
+		return cli.Exit("", int(exitCodeOK))
+	}
+
+	return runDownloads(ctx.Context, conf, jobs, settings.concurrency, isTerminal(os.Stdout), os.Stdout, os.Stderr)
+}
+
+func downloadSettingsFromFlags(ctx *cli.Context, conf *config.DownloadConfig) (*downloadSettings, error) {
+	settings, err := newDownloadSettings(conf)
+	if err != nil {
+		return nil, err
+	}
+
+	if dir := ctx.String("dir"); dir != "" {
+		settings.dir = expandHome(dir)
+	}
+
+	if layout := ctx.String("layout"); layout != "" {
+		if err := download.ValidateLayout(layout); err != nil {
+			return nil, cli.Exit(
+				fmt.Sprintf("invalid value of --layout: %s", err),
+				int(exitCodeErrArgs),
+			)
+		}
+
+		settings.layout = layout
+	}
+
+	if n := ctx.Int("concurrency"); n > 0 {
+		settings.concurrency = n
+	}
+
+	return settings, nil
+}
+
+// newDownloadSettings returns the download settings in the config with the defaults.
+func newDownloadSettings(conf *config.DownloadConfig) (*downloadSettings, error) {
+	settings := &downloadSettings{
+		dir:         expandHome(conf.Dir),
+		layout:      conf.Layout,
+		concurrency: conf.Concurrency,
+	}
+
+	if settings.dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, cli.Exit(
+				fmt.Sprintf("failed to find the home directory, set download.dir in the config file: %s", err),
+				int(exitCodeErrConfig),
+			)
+		}
+
+		settings.dir = filepath.Join(home, "Podcasts")
+	}
+
+	if settings.layout == "" {
+		settings.layout = download.DefaultLayout
+	} else if err := download.ValidateLayout(settings.layout); err != nil {
+		return nil, cli.Exit(
+			fmt.Sprintf("invalid download layout in config: %s", err),
+			int(exitCodeErrConfig),
+		)
+	}
+
+	if settings.concurrency < 1 {
+		settings.concurrency = defaultDownloadConcurrency
+	}
+
+	return settings, nil
+}
+
+func expandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+
+	return filepath.Join(home, strings.TrimPrefix(path, "~"))
+}
+
+// selectDownloadItems returns the items given by the IDs, the flags or the rules, or selected in the fuzzyfinder.
+func selectDownloadItems(ctx *cli.Context, conf *config.Config, items []*cache.Item) ([]*cache.Item, error) {
+	feeds := ctx.StringSlice("feed")
+	filtered := len(feeds) != 0 || ctx.String("query") != "" || ctx.String("folder") != ""
+
+	if ctx.Bool("auto") {
+		if ctx.NArg() != 0 || filtered {
+			return nil, cli.Exit(
+				"cannot specify --auto with IDs, --feed, --query or --folder",
+				int(exitCodeErrArgs),
+			)
+		}
+
+		return applyDownloadRules(items, conf.Download.Rules)
+	}
+
+	if !filtered {
+		return selectItems(ctx, items)
+	}
+
+	if ctx.NArg() != 0 {
+		return nil, cli.Exit(
+			"cannot specify both IDs and --feed, --query or --folder",
+			int(exitCodeErrArgs),
+		)
+	}
+
+	if len(feeds) != 0 {
+		matched := make([]*cache.Item, 0, len(items))
+
+		for _, feed := range feeds {
+			//nolint:exhaustruct,exhaustivestruct
+			matched = append(matched, filter.Apply(items, &filter.Filter{Feed: strings.TrimSpace(feed)})...)
+		}
+
+		items = matched
+	}
+
+	query, err := queryFromFlags(ctx, conf)
+	if err != nil {
+		return nil, err
+	}
+
+	if query != nil {
+		items = filter.Apply(items, query)
+	}
+
+	return items, nil
+}
+
+// applyDownloadRules returns the items selected by any of the rules.
+func applyDownloadRules(items []*cache.Item, rules []config.DownloadRule) ([]*cache.Item, error) {
+	selected := make([]*cache.Item, 0, len(items))
+	seen := make(map[string]bool)
+
+	for _, rule := range rules {
+		//nolint:exhaustruct,exhaustivestruct
+		matched := filter.Apply(items, &filter.Filter{Feed: rule.Feed})
+
+		if rule.Query != "" {
+			query, err := filter.ParseQuery(rule.Query, time.Now())
+			if err != nil {
+				return nil, cli.Exit(
+					fmt.Sprintf("invalid query of the download rule (%s): %s", rule.Feed, err),
+					int(exitCodeErrConfig),
+				)
+			}
+
+			matched = filter.Apply(matched, query)
+		}
+
+		if rule.Latest > 0 && rule.Latest < len(matched) {
+			sort.SliceStable(matched, func(i, j int) bool {
+				a, b := filter.Date(matched[i]), filter.Date(matched[j])
+
+				return a != nil && (b == nil || a.After(*b))
+			})
+
+			matched = matched[:rule.Latest]
+		}
+
+		for _, item := range matched {
+			if !seen[item.Key()] {
+				seen[item.Key()] = true
+				selected = append(selected, item)
+			}
+		}
+	}
+
+	return selected, nil
+}
+
+// autoDownload downloads the enclosures of the items selected by the download rules in the config, if any.
+// The queue is shown if interactive is true, and the results are printed to w otherwise. The failures are printed to errw.
+func autoDownload(ctx context.Context, items []*cache.Item, interactive bool, w, errw io.Writer) error {
+	conf, err := config.Load()
+	if err != nil {
+		return cli.Exit(
+			fmt.Sprintf("failed to load config: %s", err),
+			int(exitCodeErrConfig),
+		)
+	}
+
+	if len(conf.Download.Rules) == 0 {
+		return nil
+	}
+
+	settings, err := newDownloadSettings(&conf.Download)
+	if err != nil {
+		return err
+	}
+
+	items, err = applyDownloadRules(filter.Apply(items, hasEnclosures{}), conf.Download.Rules)
+	if err != nil {
+		return err
+	}
+
+	// The files downloaded before are excluded so that nothing is printed unless there are new enclosures.
+	jobs := download.Plan(items, settings.dir, settings.layout)
+	pending := make([]*download.Job, 0, len(jobs))
+
+	for _, job := range jobs {
+		if _, err := os.Stat(job.Path); err != nil {
+			pending = append(pending, job)
+		}
+	}
+
+	if len(pending) == 0 {
+		return nil
+	}
+
+	return runDownloads(ctx, conf, pending, settings.concurrency, interactive, w, errw)
+}
+
+// runDownloads downloads the jobs showing the queue if interactive is true, or printing the result of each job to w otherwise.
+// The failures are printed to errw. The enclosures are downloaded with the HTTP settings of their feeds in the config.
+// Interrupting the command or canceling the context stops the downloads, which are resumed next time.
+func runDownloads(
+	ctx context.Context, conf *config.Config, jobs []*download.Job, concurrency int, interactive bool, w, errw io.Writer,
+) error {
+	fetchers, err := newFeedFetchers(conf)
+	if err != nil {
+		return err
+	}
+
+	for _, job := range jobs {
+		if job.Client, err = fetchers.client(job.Item.FeedURL); err != nil {
+			return cli.Exit(err.Error(), int(exitCodeErrConfig))
+		}
+	}
+
+	ctx, cancel := signal.NotifyContext(ctx, os.Interrupt)
+	defer cancel()
+
+	events := make(chan download.Event)
+
+	go download.Run(ctx, jobs, concurrency, events)
+
+	var states []download.Event
+
+	if interactive {
+		states, err = ui.ShowDownloads(jobs, events, cancel)
+		if err != nil {
+			// Wait for the downloads to stop so that the partial files are closed.
+			cancel()
+
+			for range events {
+			}
+
+			return cli.Exit(err.Error(), int(exitCodeErrDownload))
+		}
+	} else {
+		states = printDownloads(w, jobs, events)
+	}
+
+	return reportDownloads(w, errw, jobs, states)
+}
+
+// printDownloads prints each job when it is finished and returns the last states of the jobs.
+func printDownloads(w io.Writer, jobs []*download.Job, events <-chan download.Event) []download.Event {
+	states := make([]download.Event, len(jobs))
+
+	for event := range events {
+		states[event.Index] = event
+
+		//nolint:exhaustive
+		switch event.Status {
+		case download.StatusDone:
+			fmt.Fprintf(w, "Downloaded: %s\n", jobs[event.Index].Path)
+		case download.StatusSkipped:
+			fmt.Fprintf(w, "Skipped (already exists): %s\n", jobs[event.Index].Path)
+		}
+	}
+
+	return states
+}
+
+// reportDownloads prints the summary of the downloads to w and the failures to errw,
+// and returns an error if any of them failed.
+func reportDownloads(w, errw io.Writer, jobs []*download.Job, states []download.Event) error {
+	counts := make(map[download.Status]int)
+
+	for i, state := range states {
+		counts[state.Status]++
+
+		if state.Status == download.StatusFailed {
+			fmt.Fprintf(errw, "Failed: %s: %s\n", jobs[i].Item.Title, state.Err)
+		}
+	}
+
+	fmt.Fprintf(w, "%d downloaded, %d skipped, %d failed, %d not started\n",
+		counts[download.StatusDone], counts[download.StatusSkipped],
+		counts[download.StatusFailed], counts[download.StatusPending]+counts[download.StatusActive])
+
+	if counts[download.StatusFailed] != 0 || counts[download.StatusPending]+counts[download.StatusActive] != 0 {
+		return cli.Exit("", int(exitCodeErrDownload))
+	}
+
+	return nil
+}
+
+// hasEnclosures matches the items with any enclosures.
+type hasEnclosures struct{}
+
+func (hasEnclosures) Match(item *cache.Item) bool {
+	for _, enclosure := range item.Enclosures {
+		if enclosure != nil && enclosure.URL != "" {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isTerminal(file *os.File) bool {
+	info, err := file.Stat()
+	if err != nil {
+		return false
+	}
+
+	return info.Mode()&os.ModeCharDevice != 0
+}
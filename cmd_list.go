@@ -0,0 +1,94 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/sheepla/srss/config"
+	"github.com/sheepla/srss/redact"
+	"github.com/sheepla/srss/urlentry"
+	"github.com/urfave/cli/v2"
+)
+
+//nolint:exhaustruct,exhaustivestruct
+func listCommand() *cli.Command {
+	return &cli.Command{
+		Name:    "list",
+		Aliases: []string{"l"},
+		Usage:   "List the feeds with when they were fetched and are due next",
+		Action:  runListCommand,
+	}
+}
+
+func runListCommand(ctx *cli.Context) error {
+	if ctx.NArg() != 0 {
+		return cli.Exit(
+			fmt.Sprintf("extra arguments (%s)", ctx.Args().Slice()),
+			int(exitCodeErrArgs),
+		)
+	}
+
+	urls, err := urlentry.Load()
+	if err != nil {
+		return cli.Exit(
+			fmt.Sprintf("failed to load URL entry: %s", err),
+			int(exitCodeErrURLEntry),
+		)
+	}
+
+	conf, err := config.Load()
+	if err != nil {
+		return cli.Exit(
+			fmt.Sprintf("failed to load config: %s", err),
+			int(exitCodeErrConfig),
+		)
+	}
+
+	feeds, err := loadFeeds()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+
+	formatTime := func(t time.Time) string {
+		return t.Local().Format("2006-01-02 15:04")
+	}
+
+	//nolint:gomnd
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(w, "FEED\tURL\tFETCHED\tNEXT")
+
+	for _, url := range urls {
+		feed := feeds[url]
+		if feed == nil || feed.FetchedAt.IsZero() {
+			fmt.Fprintf(w, "-\t%s\t-\tnow\n", redact.URL(url))
+
+			continue
+		}
+
+		title := feed.Title
+		if title == "" {
+			title = "-"
+		}
+
+		// An invalid interval is reported by update.
+		interval, _ := feedInterval(conf, url, "", 0)
+
+		next := "now"
+		if due := nextFetch(feed, interval); due.After(now) {
+			next = formatTime(due)
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", title, redact.URL(url), formatTime(feed.FetchedAt), next)
+	}
+
+	if err := w.Flush(); err != nil {
+		return cli.Exit(fmt.Sprintf("failed to print the feeds: %s", err), int(exitCodeErrOutput))
+	}
+
+	return nil
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/sheepla/srss/cache"
+	"github.com/sheepla/srss/search"
+	"github.com/sheepla/srss/ui"
+	"github.com/urfave/cli/v2"
+)
+
+//nolint:exhaustruct,exhaustivestruct
+func searchFlags() []cli.Flag {
+	return append([]cli.Flag{
+		&cli.BoolFlag{
+			Name:    "interactive",
+			Aliases: []string{"i"},
+			Usage:   "Browse the results with fuzzyfinder and pager, ask for the terms if not given",
+		},
+	}, outputFlags()...)
+}
+
+func runSearchCommand(ctx *cli.Context) error {
+	items, err := loadItems()
+	if err != nil {
+		return err
+	}
+
+	index, err := loadSearchIndex(items)
+	if err != nil {
+		return err
+	}
+
+	terms := strings.Join(ctx.Args().Slice(), " ")
+
+	if !ctx.Bool("interactive") {
+		if strings.TrimSpace(terms) == "" {
+			return cli.Exit(
+				"requires search terms as arguments",
+				int(exitCodeErrArgs),
+			)
+		}
+
+		return writeItems(ctx, searchItems(index, items, terms))
+	}
+
+	if strings.TrimSpace(terms) == "" {
+		return searchInteractive(index, items)
+	}
+
+	if err := browseItems(searchItems(index, items, terms), nil); err != nil {
+		return err
+	}
+
+	return cli.Exit("quit", int(exitCodeOK))
+}
+
+// loadSearchIndex loads the search index built by the update command,
+// or builds it from the items if it does not exist yet.
+func loadSearchIndex(items []*cache.Item) (*search.Index, error) {
+	index, err := search.Load()
+	if err == nil {
+		return index, nil
+	}
+
+	if !errors.Is(err, os.ErrNotExist) {
+		return nil, cli.Exit(
+			fmt.Sprintf("failed to load the search index: %s", err),
+			int(exitCodeErrCache),
+		)
+	}
+
+	index = search.Build(items)
+	if err := index.Save(); err != nil {
+		return nil, cli.Exit(
+			fmt.Sprintf("failed to save the search index: %s", err),
+			int(exitCodeErrCache),
+		)
+	}
+
+	return index, nil
+}
+
+// searchInteractive asks the user for the search terms and browses the results until the prompt is canceled.
+func searchInteractive(index *search.Index, items []*cache.Item) error {
+	for {
+		terms, err := ui.Prompt("Search articles", "terms in the title, description or content")
+		if err != nil {
+			if errors.Is(err, ui.ErrPromptCanceled) {
+				return nil
+			}
+
+			return cli.Exit(
+				fmt.Sprintf("an error occurred on prompt: %s", err),
+				int(exitCodeErrFuzzyFinder),
+			)
+		}
+
+		if strings.TrimSpace(terms) == "" {
+			continue
+		}
+
+		if err := browseItems(searchItems(index, items, terms), nil); err != nil {
+			return err
+		}
+	}
+}
+
+func searchItems(index *search.Index, items []*cache.Item, terms string) []*cache.Item {
+	results := index.Search(items, terms)
+	matched := make([]*cache.Item, 0, len(results))
+
+	for _, result := range results {
+		matched = append(matched, result.Item)
+	}
+
+	return matched
+}
@@ -0,0 +1,241 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/kirsle/configdir"
+)
+
+//nolint:gochecknoglobals
+var configFile = filepath.Join(configdir.LocalConfig(), "srss", "config.json")
+
+// Config is the user configuration loaded from config.json in the config directory.
+type Config struct {
+	// Queries are the saved filter expressions shown as smart folders.
+	Queries []SavedQuery `json:"queries,omitempty"`
+	// Time is how the dates of the items are displayed.
+	Time TimeConfig `json:"time,omitempty"`
+	// Player is the command to play the enclosures, e.g. "mpv --no-video".
+	// The URL replaces {url} in the command, or is appended to it.
+	Player string `json:"player,omitempty"`
+	// Download is where and how the enclosures are downloaded.
+	Download DownloadConfig `json:"download,omitempty"`
+	// Opener is the command to open the links, e.g. "firefox --new-tab {url}" or "w3m {url}".
+	// The default browser of the system is used if empty.
+	Opener string `json:"opener,omitempty"`
+	// Interval is how often each feed is fetched by update and the daemon, e.g. "30m" or "2h".
+	Interval string `json:"interval,omitempty"`
+	// Fetch is the timeouts and retries to fetch the feeds.
+	Fetch FetchConfig `json:"fetch,omitempty"`
+	// HTTP is the settings of the HTTP client to fetch the feeds, the full texts and the enclosures.
+	HTTP HTTPConfig `json:"http,omitempty"`
+	// Feeds are the settings overridden per feed.
+	Feeds []FeedConfig `json:"feeds,omitempty"`
+	// Hooks are the commands run with the new items after the feeds are updated.
+	Hooks []HookConfig `json:"hooks,omitempty"`
+}
+
+// HookConfig is a command run with the new items which match it after the feeds are updated.
+type HookConfig struct {
+	// Command is the command and its arguments separated by spaces, which is run without a shell, e.g. "~/bin/notify".
+	Command string `json:"command"`
+	// Input is "json" to pass the items in JSON lines on the stdin (default),
+	// or "env" to run the command for each item with the environment variables such as $SRSS_TITLE.
+	Input string `json:"input,omitempty"`
+	// Feed is matched against the title and URL of the feed, all feeds if empty.
+	Feed string `json:"feed,omitempty"`
+	// Query is an optional filter expression to narrow down the items.
+	Query string `json:"query,omitempty"`
+}
+
+// FeedConfig is the settings of the feed with the URL, which override the global ones.
+type FeedConfig struct {
+	// URL is the URL of the feed as in the URL entry file.
+	URL      string `json:"url"`
+	Opener   string `json:"opener,omitempty"`
+	Interval string `json:"interval,omitempty"`
+	// FullText fetches the linked pages of the items and extracts the articles, for the feeds which only have a summary.
+	FullText bool `json:"full_text,omitempty"`
+	// HTTP overrides the non-empty settings of the HTTP client, and adds the headers.
+	HTTP HTTPConfig `json:"http,omitempty"`
+	// Auth is the credentials of the feed, which are sent only to the host of the feed.
+	Auth *AuthConfig `json:"auth,omitempty"`
+}
+
+// AuthConfig is the credentials of a feed.
+type AuthConfig struct {
+	// Type is "basic", "bearer" or "cookie".
+	Type string `json:"type"`
+	// Username is the user of the basic auth.
+	Username string `json:"username,omitempty"`
+	// Secret is the password of the basic auth, the bearer token or the Cookie header.
+	Secret string `json:"secret,omitempty"`
+	// SecretCommand prints the secret instead of storing it in the config, e.g. "pass show wiki".
+	// The first line of the output is used.
+	SecretCommand string `json:"secret_command,omitempty"`
+}
+
+// HTTPConfig is the settings of the HTTP client.
+type HTTPConfig struct {
+	// UserAgent is the User-Agent header of the requests, e.g. "Mozilla/5.0".
+	UserAgent string `json:"user_agent,omitempty"`
+	// Headers are added to the requests, e.g. {"Accept-Language": "ja"}.
+	Headers map[string]string `json:"headers,omitempty"`
+	// Proxy is the URL of the proxy, e.g. "http://proxy:8080" or "socks5://localhost:1080".
+	// $HTTP_PROXY, $HTTPS_PROXY and $NO_PROXY are used if empty.
+	Proxy string `json:"proxy,omitempty"`
+	// CABundle is the path of the PEM file of the CA certificates trusted in addition to the system ones.
+	CABundle string `json:"ca_bundle,omitempty"`
+}
+
+// DownloadConfig is where and how the enclosures are downloaded.
+type DownloadConfig struct {
+	// Dir is the directory of the downloaded files, ~/Podcasts by default.
+	Dir string `json:"dir,omitempty"`
+	// Layout is the path of the files relative to Dir, e.g. "{feed}/{date}-{title}.{ext}".
+	Layout string `json:"layout,omitempty"`
+	// Concurrency is the number of files downloaded at a time.
+	Concurrency int `json:"concurrency,omitempty"`
+	// Rules select the items whose enclosures are downloaded automatically after updating the feeds.
+	Rules []DownloadRule `json:"rules,omitempty"`
+}
+
+// FetchConfig is the timeouts and retries to fetch the feeds.
+// The durations are like "10s" or "5m".
+type FetchConfig struct {
+	// ConnectTimeout limits the time to connect to the server, 10s by default.
+	ConnectTimeout string `json:"connect_timeout,omitempty"`
+	// ReadTimeout limits the time to wait for the server to respond or send more data, 30s by default.
+	ReadTimeout string `json:"read_timeout,omitempty"`
+	// Retries is the number of retries on timeouts, 5xx and 429 responses, 2 by default.
+	Retries *int `json:"retries,omitempty"`
+	// Deadline limits the time to fetch all feeds in an update, 10m by default.
+	Deadline string `json:"deadline,omitempty"`
+}
+
+// DownloadRule selects the items of a feed to download automatically.
+type DownloadRule struct {
+	// Feed is matched against the title and URL of the feed.
+	Feed string `json:"feed"`
+	// Query is an optional filter expression to narrow down the items.
+	Query string `json:"query,omitempty"`
+	// Latest limits the items to the newest ones, all matched items if zero.
+	Latest int `json:"latest,omitempty"`
+}
+
+// TimeConfig is how the dates of the items are displayed.
+type TimeConfig struct {
+	// Format is "relative" (e.g. "3h ago", default) or "absolute".
+	Format string `json:"format,omitempty"`
+	// Layout is the Go time layout of the absolute dates (e.g. "2006-01-02 15:04").
+	Layout string `json:"layout,omitempty"`
+	// Timezone is the IANA time zone name of the absolute dates (e.g. "Asia/Tokyo"), the local time zone by default.
+	Timezone string `json:"timezone,omitempty"`
+	// Locale is the language of the relative dates, "en" or "ja". It defaults to the one of $LANG.
+	Locale string `json:"locale,omitempty"`
+}
+
+// SavedQuery is a named filter expression.
+type SavedQuery struct {
+	Name  string `json:"name"`
+	Query string `json:"query"`
+}
+
+// Path returns the path of the config file.
+func Path() string {
+	return configFile
+}
+
+// Load reads the config file.
+// If the file does not exist, the default config is returned.
+func Load() (*Config, error) {
+	//nolint:exhaustruct,exhaustivestruct
+	conf := &Config{}
+
+	data, err := os.ReadFile(configFile)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return conf, nil
+		}
+
+		return nil, fmt.Errorf("failed to read the config file (%s): %w", configFile, err)
+	}
+
+	if err := json.Unmarshal(data, conf); err != nil {
+		return nil, fmt.Errorf("failed to parse the config file (%s): %w", configFile, err)
+	}
+
+	return conf, nil
+}
+
+// Feed returns the settings of the feed with the URL, or nil if there is none.
+func (conf *Config) Feed(url string) *FeedConfig {
+	for i := range conf.Feeds {
+		if conf.Feeds[i].URL == url {
+			return &conf.Feeds[i]
+		}
+	}
+
+	return nil
+}
+
+// OpenerFor returns the opener command for the links of the feed with the URL.
+func (conf *Config) OpenerFor(url string) string {
+	if feed := conf.Feed(url); feed != nil && feed.Opener != "" {
+		return feed.Opener
+	}
+
+	return conf.Opener
+}
+
+// HTTPFor returns the settings of the HTTP client for the feed with the URL,
+// which are the global ones overridden by the ones of the feed.
+func (conf *Config) HTTPFor(url string) HTTPConfig {
+	settings := conf.HTTP
+
+	feed := conf.Feed(url)
+	if feed == nil {
+		return settings
+	}
+
+	if feed.HTTP.UserAgent != "" {
+		settings.UserAgent = feed.HTTP.UserAgent
+	}
+
+	if feed.HTTP.Proxy != "" {
+		settings.Proxy = feed.HTTP.Proxy
+	}
+
+	if feed.HTTP.CABundle != "" {
+		settings.CABundle = feed.HTTP.CABundle
+	}
+
+	if len(feed.HTTP.Headers) != 0 {
+		settings.Headers = make(map[string]string, len(conf.HTTP.Headers)+len(feed.HTTP.Headers))
+
+		for name, value := range conf.HTTP.Headers {
+			settings.Headers[name] = value
+		}
+
+		for name, value := range feed.HTTP.Headers {
+			settings.Headers[name] = value
+		}
+	}
+
+	return settings
+}
+
+// FindQuery returns the saved query with the name.
+func (conf *Config) FindQuery(name string) (*SavedQuery, bool) {
+	for i := range conf.Queries {
+		if conf.Queries[i].Name == name {
+			return &conf.Queries[i], true
+		}
+	}
+
+	return nil, false
+}
@@ -0,0 +1,445 @@
+package download
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/mmcdole/gofeed"
+	"github.com/sheepla/srss/cache"
+	"github.com/sheepla/srss/redact"
+)
+
+// DefaultLayout is the default path of the downloaded files relative to the download directory.
+const DefaultLayout = "{feed}/{date}-{title}.{ext}"
+
+const (
+	partSuffix    = ".part"
+	maxNameLength = 100
+	dirPerm       = 0o755
+	filePerm      = 0o644
+)
+
+var (
+	// ErrInvalidLayout is returned when the layout has an unknown placeholder or is not a relative path.
+	ErrInvalidLayout = errors.New("invalid layout")
+	// ErrStatus is returned when the server responds with an unexpected status code.
+	ErrStatus = errors.New("unexpected status")
+)
+
+// Job is an enclosure to download to the path.
+type Job struct {
+	Item *cache.Item
+	URL  string
+	Path string
+	// Client is the HTTP client to download the file, http.DefaultClient if nil.
+	Client *http.Client
+}
+
+// Status is the state of a job.
+type Status int
+
+const (
+	StatusPending Status = iota
+	StatusActive
+	StatusDone
+	StatusSkipped
+	StatusFailed
+)
+
+func (s Status) String() string {
+	switch s {
+	case StatusPending:
+		return "pending"
+	case StatusActive:
+		return "downloading"
+	case StatusDone:
+		return "done"
+	case StatusSkipped:
+		return "skipped"
+	case StatusFailed:
+		return "failed"
+	}
+
+	return "unknown"
+}
+
+// Event reports the progress of the job at Index.
+// Total is -1 if the size is unknown.
+type Event struct {
+	Index  int
+	Status Status
+	Done   int64
+	Total  int64
+	Err    error
+}
+
+// ValidateLayout checks that the layout is a path in the download directory and its placeholders are known.
+func ValidateLayout(layout string) error {
+	if layout == "" || filepath.IsAbs(layout) {
+		return fmt.Errorf("%w: must be a relative path (%s)", ErrInvalidLayout, layout)
+	}
+
+	// The values of the placeholders cannot go up since they are sanitized, see Expand.
+	if cleaned := filepath.Clean(filepath.FromSlash(layout)); cleaned == ".." ||
+		strings.HasPrefix(cleaned, ".."+string(filepath.Separator)) {
+		return fmt.Errorf("%w: must be in the download directory (%s)", ErrInvalidLayout, layout)
+	}
+
+	rest := layout
+	for {
+		start := strings.Index(rest, "{")
+		if start == -1 {
+			return nil
+		}
+
+		end := strings.Index(rest[start:], "}")
+		if end == -1 {
+			return fmt.Errorf("%w: unclosed placeholder (%s)", ErrInvalidLayout, layout)
+		}
+
+		name := rest[start+1 : start+end]
+		if !contains(placeholders(), name) {
+			return fmt.Errorf("%w: unknown placeholder {%s}, must be one of %s",
+				ErrInvalidLayout, name, strings.Join(placeholders(), ", "))
+		}
+
+		rest = rest[start+end+1:]
+	}
+}
+
+func placeholders() []string {
+	return []string{"feed", "date", "title", "id", "ext"}
+}
+
+// Plan returns the jobs to download the enclosures of the items into the directory with the layout.
+// If an item has more than one enclosure, the number of the enclosure is appended to the name of the second and later ones.
+// A number is also appended to the names used by the jobs before, e.g. of the items with the same title and date,
+// so that no two jobs write the same file.
+func Plan(items []*cache.Item, dir, layout string) []*Job {
+	jobs := make([]*Job, 0, len(items))
+	used := make(map[string]bool)
+
+	for _, item := range items {
+		n := 0
+
+		for _, enclosure := range item.Enclosures {
+			if enclosure == nil || enclosure.URL == "" {
+				continue
+			}
+
+			n++
+
+			name := Expand(layout, item, enclosure)
+			if n > 1 {
+				name = numbered(name, n)
+			}
+
+			for i := 2; used[name]; i++ {
+				name = numbered(Expand(layout, item, enclosure), i)
+			}
+
+			used[name] = true
+
+			jobs = append(jobs, &Job{Item: item, URL: enclosure.URL, Path: filepath.Join(dir, name), Client: nil})
+		}
+	}
+
+	return jobs
+}
+
+// numbered appends the number to the name before the extension.
+func numbered(name string, n int) string {
+	ext := filepath.Ext(name)
+
+	return fmt.Sprintf("%s-%d%s", strings.TrimSuffix(name, ext), n, ext)
+}
+
+// Expand replaces the placeholders of the layout with the values of the item and enclosure.
+// Each value is sanitized to be a single file name.
+func Expand(layout string, item *cache.Item, enclosure *gofeed.Enclosure) string {
+	date := "undated"
+	if t := itemDate(item); t != nil {
+		date = t.Local().Format("2006-01-02")
+	}
+
+	feed := item.FeedTitle
+	if feed == "" {
+		feed = item.FeedURL
+	}
+
+	replacer := strings.NewReplacer(
+		"{feed}", sanitize(feed),
+		"{date}", date,
+		"{title}", sanitize(item.Title),
+		"{id}", item.ID(),
+		"{ext}", extension(enclosure),
+	)
+
+	return filepath.FromSlash(replacer.Replace(layout))
+}
+
+func itemDate(item *cache.Item) *time.Time {
+	if item.PublishedParsed != nil {
+		return item.PublishedParsed
+	}
+
+	return item.UpdatedParsed
+}
+
+// extension returns the extension of the enclosure without the dot from the URL or the MIME type.
+func extension(enclosure *gofeed.Enclosure) string {
+	urlPath := enclosure.URL
+	if i := strings.IndexAny(urlPath, "?#"); i != -1 {
+		urlPath = urlPath[:i]
+	}
+
+	if ext := strings.TrimPrefix(path.Ext(urlPath), "."); ext != "" && len(ext) <= 5 && isAlnum(ext) {
+		return strings.ToLower(ext)
+	}
+
+	if exts, err := mime.ExtensionsByType(enclosure.Type); err == nil && len(exts) != 0 {
+		return strings.TrimPrefix(exts[0], ".")
+	}
+
+	return "bin"
+}
+
+func isAlnum(s string) bool {
+	for _, r := range s {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// sanitize makes the string usable as a file name on all platforms.
+func sanitize(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) || strings.ContainsRune(`/\:*?"<>|`, r) {
+			return '_'
+		}
+
+		return r
+	}, strings.TrimSpace(name))
+
+	if runes := []rune(name); len(runes) > maxNameLength {
+		name = string(runes[:maxNameLength])
+	}
+
+	name = strings.Trim(name, " .")
+	if name == "" {
+		return "untitled"
+	}
+
+	return name
+}
+
+// Run downloads the jobs with at most concurrency downloads at a time and sends the progress to events.
+// The events channel is closed when all jobs are finished.
+func Run(ctx context.Context, jobs []*Job, concurrency int, events chan<- Event) {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	queue := make(chan int)
+
+	var wg sync.WaitGroup
+
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for i := range queue {
+				runJob(ctx, i, jobs[i], events)
+			}
+		}()
+	}
+
+	for i := range jobs {
+		select {
+		case queue <- i:
+		case <-ctx.Done():
+		}
+	}
+
+	close(queue)
+	wg.Wait()
+	close(events)
+}
+
+func runJob(ctx context.Context, index int, job *Job, events chan<- Event) {
+	if ctx.Err() != nil {
+		events <- Event{Index: index, Status: StatusFailed, Done: 0, Total: -1, Err: ctx.Err()}
+
+		return
+	}
+
+	if _, err := os.Stat(job.Path); err == nil {
+		events <- Event{Index: index, Status: StatusSkipped, Done: 0, Total: -1, Err: nil}
+
+		return
+	}
+
+	events <- Event{Index: index, Status: StatusActive, Done: 0, Total: -1, Err: nil}
+
+	client := job.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	var done, total int64
+
+	err := Fetch(ctx, client, job.URL, job.Path, func(d, t int64) {
+		done, total = d, t
+		events <- Event{Index: index, Status: StatusActive, Done: d, Total: t, Err: nil}
+	})
+	if err != nil {
+		events <- Event{Index: index, Status: StatusFailed, Done: done, Total: total, Err: err}
+
+		return
+	}
+
+	events <- Event{Index: index, Status: StatusDone, Done: done, Total: total, Err: nil}
+}
+
+// Fetch downloads the URL to the path.
+// The data is written to the path with the .part suffix first, and the download is resumed from it if it exists.
+// The progress is reported with the number of bytes written and the total size, which is -1 if unknown.
+func Fetch(ctx context.Context, client *http.Client, url, dest string, progress func(done, total int64)) error {
+	if err := os.MkdirAll(filepath.Dir(dest), dirPerm); err != nil {
+		return fmt.Errorf("failed to create the directory: %w", err)
+	}
+
+	part := dest + partSuffix
+
+	var offset int64
+	if info, err := os.Stat(part); err == nil {
+		offset = info.Size()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create the request: %w", err)
+	}
+
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to download: %w", redact.Error(err))
+	}
+	defer resp.Body.Close()
+
+	flag := os.O_WRONLY | os.O_CREATE
+
+	switch {
+	case resp.StatusCode == http.StatusPartialContent && rangeStart(resp) == offset:
+		flag |= os.O_APPEND
+	case resp.StatusCode == http.StatusRequestedRangeNotSatisfiable && offset > 0:
+		// The partial file is already complete.
+		return finish(part, dest)
+	case resp.StatusCode == http.StatusOK:
+		offset = 0
+		flag |= os.O_TRUNC
+	default:
+		return fmt.Errorf("%w: %s (%s)", ErrStatus, resp.Status, redact.URL(url))
+	}
+
+	total := int64(-1)
+	if resp.ContentLength >= 0 {
+		total = offset + resp.ContentLength
+	}
+
+	file, err := os.OpenFile(part, flag, filePerm)
+	if err != nil {
+		return fmt.Errorf("failed to open the file: %w", err)
+	}
+
+	writer := &progressWriter{done: offset, total: total, last: time.Time{}, report: progress}
+	progress(offset, total)
+
+	if _, err := io.Copy(io.MultiWriter(file, writer), resp.Body); err != nil {
+		// The partial file is kept to resume the download next time.
+		file.Close()
+
+		return fmt.Errorf("failed to download (%s): %w", redact.URL(url), err)
+	}
+
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to close the file: %w", err)
+	}
+
+	return finish(part, dest)
+}
+
+func finish(part, dest string) error {
+	if err := os.Rename(part, dest); err != nil {
+		return fmt.Errorf("failed to rename the file: %w", err)
+	}
+
+	return nil
+}
+
+// rangeStart returns the first byte position of the Content-Range header, or -1 if it is invalid.
+func rangeStart(resp *http.Response) int64 {
+	value := strings.TrimPrefix(resp.Header.Get("Content-Range"), "bytes ")
+
+	end := strings.IndexAny(value, "-/")
+	if end == -1 {
+		return -1
+	}
+
+	start, err := strconv.ParseInt(value[:end], 10, 64)
+	if err != nil {
+		return -1
+	}
+
+	return start
+}
+
+type progressWriter struct {
+	done, total int64
+	last        time.Time
+	report      func(done, total int64)
+}
+
+const reportInterval = 100 * time.Millisecond
+
+func (w *progressWriter) Write(p []byte) (int, error) {
+	w.done += int64(len(p))
+
+	if now := time.Now(); now.Sub(w.last) >= reportInterval || w.done == w.total {
+		w.last = now
+		w.report(w.done, w.total)
+	}
+
+	return len(p), nil
+}
+
+func contains(list []string, v string) bool {
+	for _, item := range list {
+		if item == v {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,184 @@
+package download
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mmcdole/gofeed"
+	"github.com/sheepla/srss/cache"
+)
+
+//nolint:exhaustruct,exhaustivestruct
+func TestPlan(t *testing.T) {
+	t.Parallel()
+
+	published := time.Date(2022, 8, 15, 12, 0, 0, 0, time.Local)
+	items := []*cache.Item{
+		{Item: &gofeed.Item{
+			Title:           "#1: Hello/World?",
+			PublishedParsed: &published,
+			Enclosures:      []*gofeed.Enclosure{{URL: "https://example.com/1.mp3?t=1", Type: "audio/mpeg"}},
+		}, FeedTitle: "Go Time"},
+		{Item: &gofeed.Item{
+			Title:           "Two files",
+			PublishedParsed: &published,
+			Enclosures: []*gofeed.Enclosure{
+				{URL: "https://example.com/2", Type: "audio/mpeg"},
+				{URL: "https://example.com/2.pdf"},
+			},
+		}, FeedTitle: "Go Time"},
+		{Item: &gofeed.Item{Title: "No enclosure", PublishedParsed: &published}, FeedTitle: "Go Time"},
+		// The same title and date as the item above.
+		{Item: &gofeed.Item{
+			Title:           "Two files",
+			PublishedParsed: &published,
+			Enclosures:      []*gofeed.Enclosure{{URL: "https://example.com/3.mp3"}},
+		}, FeedTitle: "Go Time"},
+		{Item: &gofeed.Item{
+			Title:           "Two files-2",
+			PublishedParsed: &published,
+			Enclosures:      []*gofeed.Enclosure{{URL: "https://example.com/4.pdf"}},
+		}, FeedTitle: "Go Time"},
+	}
+
+	jobs := Plan(items, "lib", DefaultLayout)
+
+	want := []string{
+		filepath.Join("lib", "Go Time", "2022-08-15-#1_ Hello_World_.mp3"),
+		filepath.Join("lib", "Go Time", "2022-08-15-Two files.mp3"),
+		filepath.Join("lib", "Go Time", "2022-08-15-Two files-2.pdf"),
+		filepath.Join("lib", "Go Time", "2022-08-15-Two files-2.mp3"),
+		filepath.Join("lib", "Go Time", "2022-08-15-Two files-2-2.pdf"),
+	}
+
+	if len(jobs) != len(want) {
+		t.Fatalf("Plan() returned %d jobs, want %d", len(jobs), len(want))
+	}
+
+	for i := range want {
+		if jobs[i].Path != want[i] {
+			t.Errorf("jobs[%d].Path = %q, want %q", i, jobs[i].Path, want[i])
+		}
+	}
+}
+
+func TestValidateLayout(t *testing.T) {
+	t.Parallel()
+
+	for _, layout := range []string{DefaultLayout, "{id}.{ext}", "podcasts/{feed}/{title}.mp3"} {
+		if err := ValidateLayout(layout); err != nil {
+			t.Errorf("ValidateLayout(%q) = %v, want nil", layout, err)
+		}
+	}
+
+	for _, layout := range []string{"", "/abs/{title}.{ext}", "{author}.{ext}", "{title", "../{title}.{ext}", "{feed}/../../{title}.{ext}"} {
+		if err := ValidateLayout(layout); !errors.Is(err, ErrInvalidLayout) {
+			t.Errorf("ValidateLayout(%q) = %v, want ErrInvalidLayout", layout, err)
+		}
+	}
+}
+
+func TestFetchResume(t *testing.T) {
+	t.Parallel()
+
+	content := bytes.Repeat([]byte("0123456789"), 1000)
+
+	var ranges []string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ranges = append(ranges, r.Header.Get("Range"))
+		http.ServeContent(w, r, "ep.mp3", time.Time{}, bytes.NewReader(content))
+	}))
+	defer server.Close()
+
+	dest := filepath.Join(t.TempDir(), "feed", "ep.mp3")
+	if err := os.MkdirAll(filepath.Dir(dest), dirPerm); err != nil {
+		t.Fatal(err)
+	}
+
+	// An interrupted download left the first 4000 bytes.
+	if err := os.WriteFile(dest+partSuffix, content[:4000], filePerm); err != nil {
+		t.Fatal(err)
+	}
+
+	var done, total int64
+
+	err := Fetch(context.Background(), server.Client(), server.URL, dest, func(d, t int64) {
+		done, total = d, t
+	})
+	if err != nil {
+		t.Fatalf("Fetch() = %v", err)
+	}
+
+	if len(ranges) != 1 || ranges[0] != "bytes=4000-" {
+		t.Errorf("Range headers = %q, want [bytes=4000-]", ranges)
+	}
+
+	if done != int64(len(content)) || total != int64(len(content)) {
+		t.Errorf("progress = %d/%d, want %d/%d", done, total, len(content), len(content))
+	}
+
+	data, err := os.ReadFile(dest)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(data, content) {
+		t.Errorf("downloaded file differs from the content")
+	}
+
+	if _, err := os.Stat(dest + partSuffix); !os.IsNotExist(err) {
+		t.Errorf("partial file is left: %v", err)
+	}
+}
+
+func TestRun(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "missing.mp3") {
+			http.NotFound(w, r)
+
+			return
+		}
+
+		_, _ = w.Write([]byte("audio"))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+
+	//nolint:exhaustruct,exhaustivestruct
+	jobs := Plan([]*cache.Item{
+		{Item: &gofeed.Item{Title: "ok", Enclosures: []*gofeed.Enclosure{{URL: server.URL + "/ok.mp3"}}}},
+		{Item: &gofeed.Item{Title: "missing", Enclosures: []*gofeed.Enclosure{{URL: server.URL + "/missing.mp3"}}}},
+		{Item: &gofeed.Item{Title: "exists", Enclosures: []*gofeed.Enclosure{{URL: server.URL + "/exists.mp3"}}}},
+	}, dir, "{title}.{ext}")
+
+	if err := os.WriteFile(jobs[2].Path, []byte("old"), filePerm); err != nil {
+		t.Fatal(err)
+	}
+
+	events := make(chan Event)
+	go Run(context.Background(), jobs, 2, events)
+
+	last := make([]Status, len(jobs))
+	for event := range events {
+		last[event.Index] = event.Status
+	}
+
+	want := []Status{StatusDone, StatusFailed, StatusSkipped}
+	for i := range want {
+		if last[i] != want[i] {
+			t.Errorf("status of %s = %s, want %s", jobs[i].Item.Title, last[i], want[i])
+		}
+	}
+}
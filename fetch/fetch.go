@@ -0,0 +1,437 @@
+// Package fetch downloads and parses the feeds.
+package fetch
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/mmcdole/gofeed"
+	"github.com/sheepla/srss/redact"
+	"github.com/sheepla/srss/schedule"
+)
+
+// UserAgent is sent to the servers of the feeds.
+const UserAgent = "srss (+https://github.com/sheepla/srss)"
+
+const (
+	// maxFeedSize limits the size of the feeds read.
+	maxFeedSize = 50 << 20
+	// maxRetryDelay is the longest delay before a retry, so that a long Retry-After gives up instead.
+	maxRetryDelay = time.Minute
+)
+
+var (
+	// ErrStatus is returned when the server responds with a status other than 200 OK.
+	ErrStatus = errors.New("unexpected status")
+	// ErrReadTimeout is returned when the server sends nothing for the read timeout.
+	ErrReadTimeout = errors.New("read timeout")
+	// ErrProxy is returned by NewClient when the proxy URL is invalid.
+	ErrProxy = errors.New("invalid proxy")
+	// ErrCABundle is returned by NewClient when the CA bundle has no certificates.
+	ErrCABundle = errors.New("no certificates found in the CA bundle")
+)
+
+// StatusError is the error of a response with a status other than 200 OK.
+type StatusError struct {
+	StatusCode int
+	Status     string
+	// RetryAfter is the Retry-After header of the response, or zero if there is none.
+	RetryAfter time.Duration
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("%s: %s", ErrStatus, e.Status)
+}
+
+func (e *StatusError) Unwrap() error {
+	return ErrStatus
+}
+
+// Options are the settings of the HTTP client and the timeouts and retries of the fetches.
+type Options struct {
+	// UserAgent is the User-Agent header of the requests, UserAgent if empty.
+	UserAgent string
+	// Headers are added to the requests.
+	Headers map[string]string
+	// Proxy is the URL of the HTTP, HTTPS or SOCKS5 proxy.
+	// The proxy is taken from $HTTP_PROXY, $HTTPS_PROXY and $NO_PROXY if empty.
+	Proxy string
+	// CABundle is the path of the PEM file of the CA certificates trusted in addition to the system ones.
+	CABundle string
+	// Auth is the credentials of the feed, or nil if it needs none.
+	Auth *Auth
+	// ConnectTimeout limits the time to connect to the server including the TLS handshake.
+	ConnectTimeout time.Duration
+	// ReadTimeout limits the time to wait for the response and for each read of the body.
+	ReadTimeout time.Duration
+	// Retries is the number of retries on transient errors: timeouts, 5xx and 429 Too Many Requests.
+	Retries int
+	// RetryDelay is the delay before the first retry, which doubles with each retry.
+	// The Retry-After header of the response is used instead if any.
+	RetryDelay time.Duration
+}
+
+// The types of the credentials.
+const (
+	AuthBasic  = "basic"
+	AuthBearer = "bearer"
+	AuthCookie = "cookie"
+)
+
+// Auth is the credentials sent only to the host of the feed, and not to the other sites such as the ones of the enclosures.
+type Auth struct {
+	// Type is AuthBasic, AuthBearer or AuthCookie.
+	Type string
+	// Host is the host (and port) of the feed.
+	Host string
+	// Username is the user of the basic auth.
+	Username string
+	// Secret returns the password of the basic auth, the bearer token or the Cookie header.
+	// It is called on the first request to the host, so that e.g. a password manager is not asked until needed.
+	Secret func() (string, error)
+}
+
+// DefaultOptions returns the options used unless configured.
+func DefaultOptions() Options {
+	//nolint:gomnd
+	return Options{
+		ConnectTimeout: 10 * time.Second,
+		ReadTimeout:    30 * time.Second,
+		Retries:        2,
+		RetryDelay:     time.Second,
+	}
+}
+
+// Result is the feed fetched from the URL with the hints to schedule the next fetch.
+type Result struct {
+	URL   string
+	Feed  *gofeed.Feed
+	Hints schedule.Hints
+	// MovedTo is the URL which the feed permanently moved to, or empty if it did not.
+	MovedTo string
+}
+
+// Fetcher fetches the feeds with the options.
+type Fetcher struct {
+	client  *http.Client
+	options Options
+}
+
+// New returns a fetcher with the options.
+func New(options Options) (*Fetcher, error) {
+	client, err := NewClient(options)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Fetcher{client: client, options: options}, nil
+}
+
+// Client returns the HTTP client of the fetcher, e.g. to fetch the pages and the enclosures of the feeds.
+func (f *Fetcher) Client() *http.Client {
+	return f.client
+}
+
+// NewClient returns the HTTP client with the User-Agent, headers, proxy, CA bundle and connect timeout of the options.
+// The client has no timeout for the whole request since it also downloads large files.
+func NewClient(options Options) (*http.Client, error) {
+	//nolint:forcetypeassert
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	//nolint:exhaustruct,exhaustivestruct
+	transport.DialContext = (&net.Dialer{Timeout: options.ConnectTimeout}).DialContext
+	transport.TLSHandshakeTimeout = options.ConnectTimeout
+
+	if options.Proxy != "" {
+		// The proxy is left out of the message if it cannot be parsed since its credentials cannot be masked.
+		proxy, err := url.Parse(options.Proxy)
+		if err != nil {
+			return nil, ErrProxy
+		}
+
+		if proxy.Host == "" {
+			return nil, fmt.Errorf("%w (%s)", ErrProxy, redact.URL(options.Proxy))
+		}
+
+		switch proxy.Scheme {
+		case "http", "https", "socks5", "socks5h":
+		default:
+			return nil, fmt.Errorf("%w (%s): the scheme must be http, https, socks5 or socks5h", ErrProxy, redact.URL(options.Proxy))
+		}
+
+		transport.Proxy = http.ProxyURL(proxy)
+	}
+
+	if options.CABundle != "" {
+		pool, err := certPool(options.CABundle)
+		if err != nil {
+			return nil, err
+		}
+
+		//nolint:exhaustruct,exhaustivestruct,gosec
+		transport.TLSClientConfig = &tls.Config{RootCAs: pool}
+	}
+
+	userAgent := options.UserAgent
+	if userAgent == "" {
+		userAgent = UserAgent
+	}
+
+	//nolint:exhaustruct,exhaustivestruct
+	return &http.Client{
+		Transport: &headerTransport{base: transport, userAgent: userAgent, headers: options.Headers, auth: options.Auth},
+	}, nil
+}
+
+// certPool returns the system certificates with the ones in the PEM file.
+func certPool(path string) (*x509.CertPool, error) {
+	pem, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the CA bundle: %w", err)
+	}
+
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("%w (%s)", ErrCABundle, path)
+	}
+
+	return pool, nil
+}
+
+// headerTransport sets the User-Agent, the headers and the credentials to the requests unless they are set already.
+type headerTransport struct {
+	base      http.RoundTripper
+	userAgent string
+	headers   map[string]string
+	auth      *Auth
+
+	once      sync.Once
+	secret    string
+	secretErr error
+}
+
+func (t *headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// The request must not be modified by a RoundTripper.
+	req = req.Clone(req.Context())
+
+	if req.Header.Get("User-Agent") == "" {
+		req.Header.Set("User-Agent", t.userAgent)
+	}
+
+	for name, value := range t.headers {
+		if req.Header.Get(name) == "" {
+			req.Header.Set(name, value)
+		}
+	}
+
+	if t.auth != nil && strings.EqualFold(req.URL.Host, t.auth.Host) {
+		if err := t.setAuth(req); err != nil {
+			return nil, err
+		}
+	}
+
+	return t.base.RoundTrip(req) //nolint:wrapcheck
+}
+
+func (t *headerTransport) setAuth(req *http.Request) error {
+	t.once.Do(func() {
+		t.secret, t.secretErr = t.auth.Secret()
+	})
+
+	if t.secretErr != nil {
+		return fmt.Errorf("failed to get the credentials: %w", t.secretErr)
+	}
+
+	switch t.auth.Type {
+	case AuthBasic:
+		req.SetBasicAuth(t.auth.Username, t.secret)
+	case AuthBearer:
+		req.Header.Set("Authorization", "Bearer "+t.secret)
+	case AuthCookie:
+		req.Header.Set("Cookie", t.secret)
+	}
+
+	return nil
+}
+
+// Fetch downloads the feed from the URL and parses it, retrying on transient errors.
+// The retries give up when the context would be done before them.
+func (f *Fetcher) Fetch(ctx context.Context, feedURL string) (*Result, error) {
+	delay := f.options.RetryDelay
+
+	for attempt := 0; ; attempt++ {
+		result, err := f.fetch(ctx, feedURL)
+		if err == nil || attempt >= f.options.Retries || ctx.Err() != nil || !retryable(err) {
+			return result, err
+		}
+
+		wait := delay
+
+		var statusErr *StatusError
+		if errors.As(err, &statusErr) && statusErr.RetryAfter > 0 {
+			wait = statusErr.RetryAfter
+		}
+
+		if deadline, ok := ctx.Deadline(); wait > maxRetryDelay || ok && time.Until(deadline) < wait {
+			return nil, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, err
+		case <-time.After(wait):
+		}
+
+		delay *= 2
+	}
+}
+
+// fetch downloads the feed once.
+func (f *Fetcher) fetch(ctx context.Context, feedURL string) (*Result, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// The timer is reset by each read of the body, and cancels the request when the server stalls.
+	var timedOut int32
+
+	timer := time.AfterFunc(f.readTimeout(), func() {
+		atomic.StoreInt32(&timedOut, 1)
+		cancel()
+	})
+	defer timer.Stop()
+
+	result, err := f.get(ctx, feedURL, func() { timer.Reset(f.readTimeout()) })
+	if err != nil && atomic.LoadInt32(&timedOut) == 1 {
+		return nil, fmt.Errorf("%w (%s)", ErrReadTimeout, f.options.ReadTimeout)
+	}
+
+	return result, err
+}
+
+func (f *Fetcher) readTimeout() time.Duration {
+	if f.options.ReadTimeout <= 0 {
+		// No timeout.
+		return math.MaxInt64
+	}
+
+	return f.options.ReadTimeout
+}
+
+func (f *Fetcher) get(ctx context.Context, feedURL string, onRead func()) (*Result, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, feedURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the request: %w", err)
+	}
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch the feed: %w", redact.Error(err))
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, &StatusError{
+			StatusCode: resp.StatusCode,
+			Status:     resp.Status,
+			RetryAfter: retryAfter(resp.Header.Get("Retry-After"), time.Now()),
+		}
+	}
+
+	raw, err := io.ReadAll(io.LimitReader(&notifyReader{r: resp.Body, onRead: onRead}, maxFeedSize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the feed: %w", err)
+	}
+
+	feed, err := gofeed.NewParser().Parse(bytes.NewReader(raw))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the feed: %w", err)
+	}
+
+	return &Result{
+		URL:     feedURL,
+		Feed:    feed,
+		Hints:   schedule.ParseHints(raw, feed, resp.Header),
+		MovedTo: movedTo(resp),
+	}, nil
+}
+
+// movedTo returns the URL of the response if it is redirected only with 301 Moved Permanently
+// and 308 Permanent Redirect, or empty otherwise.
+func movedTo(resp *http.Response) string {
+	if resp.Request.Response == nil {
+		return ""
+	}
+
+	// Each redirected request has the response which caused it.
+	for req := resp.Request; req.Response != nil; req = req.Response.Request {
+		if code := req.Response.StatusCode; code != http.StatusMovedPermanently && code != http.StatusPermanentRedirect {
+			return ""
+		}
+	}
+
+	return resp.Request.URL.String()
+}
+
+// notifyReader calls onRead after each read.
+type notifyReader struct {
+	r      io.Reader
+	onRead func()
+}
+
+func (r *notifyReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	r.onRead()
+
+	return n, err //nolint:wrapcheck
+}
+
+// retryable reports whether the error is transient.
+func retryable(err error) bool {
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode == http.StatusTooManyRequests || statusErr.StatusCode >= http.StatusInternalServerError
+	}
+
+	if errors.Is(err, ErrReadTimeout) {
+		return true
+	}
+
+	var netErr net.Error
+
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// retryAfter parses the Retry-After header, which is either seconds or an HTTP date.
+func retryAfter(value string, now time.Time) time.Duration {
+	if value == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+
+	if date, err := http.ParseTime(value); err == nil && date.After(now) {
+		return date.Sub(now)
+	}
+
+	return 0
+}
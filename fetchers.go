@@ -0,0 +1,245 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sheepla/srss/config"
+	"github.com/sheepla/srss/fetch"
+	"github.com/sheepla/srss/filter"
+	"github.com/sheepla/srss/redact"
+	"github.com/urfave/cli/v2"
+)
+
+// defaultFetchDeadline limits the time to fetch all feeds in an update.
+const defaultFetchDeadline = 10 * time.Minute
+
+// feedFetchers returns the fetchers of the feeds with the settings in the config.
+// The feeds with the same settings share a fetcher so that the connections are reused.
+type feedFetchers struct {
+	conf     *config.Config
+	options  fetch.Options
+	mu       sync.Mutex
+	fetchers map[string]*fetch.Fetcher
+}
+
+func newFeedFetchers(conf *config.Config) (*feedFetchers, error) {
+	options, err := fetchOptions(&conf.Fetch)
+	if err != nil {
+		return nil, err
+	}
+
+	//nolint:exhaustruct,exhaustivestruct
+	return &feedFetchers{conf: conf, options: options, fetchers: make(map[string]*fetch.Fetcher)}, nil
+}
+
+// get returns the fetcher of the feed with the URL.
+func (f *feedFetchers) get(url string) (*fetch.Fetcher, error) {
+	settings := f.conf.HTTPFor(url)
+
+	options := f.options
+	options.UserAgent = settings.UserAgent
+	options.Headers = settings.Headers
+	options.Proxy = settings.Proxy
+	options.CABundle = expandHome(settings.CABundle)
+
+	// fmt prints the maps sorted by the keys.
+	key := fmt.Sprintf("%+v", options)
+
+	if feed := f.conf.Feed(url); feed != nil && feed.Auth != nil {
+		auth, err := feedAuth(url, feed.Auth)
+		if err != nil {
+			return nil, fmt.Errorf("invalid auth for %s: %w", redact.URL(url), err)
+		}
+
+		options.Auth = auth
+		// The feeds with the same credentials on the same host share the fetcher, so the secret command runs once.
+		key += fmt.Sprintf(" %s %+v", auth.Host, *feed.Auth)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if fetcher, ok := f.fetchers[key]; ok {
+		return fetcher, nil
+	}
+
+	fetcher, err := fetch.New(options)
+	if err != nil {
+		return nil, fmt.Errorf("invalid HTTP settings for %s: %w", redact.URL(url), err)
+	}
+
+	f.fetchers[key] = fetcher
+
+	return fetcher, nil
+}
+
+// client returns the HTTP client for the pages and enclosures of the feed with the URL.
+func (f *feedFetchers) client(url string) (*http.Client, error) {
+	fetcher, err := f.get(url)
+	if err != nil {
+		return nil, err
+	}
+
+	return fetcher.Client(), nil
+}
+
+// feedAuth returns the credentials of the feed in the config.
+//
+//nolint:goerr113
+func feedAuth(feedURL string, conf *config.AuthConfig) (*fetch.Auth, error) {
+	u, err := url.Parse(feedURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid URL: %w", redact.Error(err))
+	}
+
+	switch conf.Type {
+	case fetch.AuthBasic:
+		if conf.Username == "" {
+			return nil, errors.New("username is required for the basic auth")
+		}
+	case fetch.AuthBearer, fetch.AuthCookie:
+	default:
+		return nil, fmt.Errorf("unknown type (%s), must be basic, bearer or cookie", conf.Type)
+	}
+
+	if (conf.Secret == "") == (conf.SecretCommand == "") {
+		return nil, errors.New("either secret or secret_command is required")
+	}
+
+	secret := func() (string, error) {
+		if conf.Secret != "" {
+			return conf.Secret, nil
+		}
+
+		return runSecretCommand(conf.SecretCommand)
+	}
+
+	return &fetch.Auth{Type: conf.Type, Host: u.Host, Username: conf.Username, Secret: secret}, nil
+}
+
+// runSecretCommand returns the first line of the output of the command.
+// The command can ask for a passphrase on the terminal, e.g. with gpg.
+func runSecretCommand(command string) (string, error) {
+	args := strings.Fields(command)
+	if len(args) == 0 {
+		//nolint:goerr113
+		return "", errors.New("the secret command is empty")
+	}
+
+	//nolint:gosec
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stdin = os.Stdin
+	cmd.Stderr = os.Stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to run the secret command (%s): %w", args[0], err)
+	}
+
+	secret := strings.TrimSpace(strings.SplitN(string(out), "\n", 2)[0]) //nolint:gomnd
+	if secret == "" {
+		//nolint:goerr113
+		return "", fmt.Errorf("the secret command (%s) printed nothing", args[0])
+	}
+
+	return secret, nil
+}
+
+// fetchOptions returns the timeouts and retries in the config.
+func fetchOptions(conf *config.FetchConfig) (fetch.Options, error) {
+	options := fetch.DefaultOptions()
+
+	for _, d := range []struct {
+		name  string
+		value string
+		dest  *time.Duration
+	}{
+		{"fetch.connect_timeout", conf.ConnectTimeout, &options.ConnectTimeout},
+		{"fetch.read_timeout", conf.ReadTimeout, &options.ReadTimeout},
+	} {
+		if d.value == "" {
+			continue
+		}
+
+		duration, err := filter.ParseDuration(d.value)
+		if err != nil {
+			return options, cli.Exit(
+				fmt.Sprintf("invalid %s in config: %s", d.name, err),
+				int(exitCodeErrConfig),
+			)
+		}
+
+		*d.dest = duration
+	}
+
+	if conf.Retries != nil {
+		if *conf.Retries < 0 {
+			return options, cli.Exit("invalid fetch.retries in config: must not be negative", int(exitCodeErrConfig))
+		}
+
+		options.Retries = *conf.Retries
+	}
+
+	return options, nil
+}
+
+// withFetchDeadline returns the context to fetch the feeds, which is done after the deadline in the flag or the config.
+// A zero deadline means no deadline.
+func withFetchDeadline(parent context.Context, conf *config.FetchConfig, flag string) (context.Context, context.CancelFunc, error) {
+	deadline := defaultFetchDeadline
+
+	if flag != "" {
+		var err error
+		if deadline, err = filter.ParseDuration(flag); err != nil {
+			return nil, nil, cli.Exit(fmt.Sprintf("invalid value of --deadline: %s", err), int(exitCodeErrArgs))
+		}
+	} else if conf.Deadline != "" {
+		var err error
+		if deadline, err = filter.ParseDuration(conf.Deadline); err != nil {
+			return nil, nil, cli.Exit(fmt.Sprintf("invalid fetch.deadline in config: %s", err), int(exitCodeErrConfig))
+		}
+	}
+
+	if deadline == 0 {
+		ctx, cancel := context.WithCancel(parent)
+
+		return ctx, cancel, nil
+	}
+
+	ctx, cancel := context.WithTimeout(parent, deadline)
+
+	return ctx, cancel, nil
+}
+
+func fetchFeed(ctx context.Context, fetchers *feedFetchers, url string) (*fetch.Result, error) {
+	fetcher, err := fetchers.get(url)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := fetcher.Fetch(ctx, url)
+	if err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			//nolint:goerr113
+			return nil, fmt.Errorf("gave up the feed at %s: the deadline passed", redact.URL(url))
+		}
+
+		if errors.Is(ctx.Err(), context.Canceled) {
+			//nolint:goerr113
+			return nil, fmt.Errorf("gave up the feed at %s: canceled", redact.URL(url))
+		}
+
+		return nil, fmt.Errorf("failed to fetch or parse feed at %s: %w", redact.URL(url), err)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,146 @@
+package filter
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sheepla/srss/cache"
+)
+
+// Filter narrows down the cached items.
+// The zero value matches every item.
+type Filter struct {
+	Feed    string
+	Since   time.Time
+	Unread  bool
+	Starred bool
+	Tags    []string
+	Text    string
+}
+
+// Apply returns the items which match the filter.
+func Apply(items []*cache.Item, matcher Matcher) []*cache.Item {
+	matched := make([]*cache.Item, 0, len(items))
+
+	for _, item := range items {
+		if matcher.Match(item) {
+			matched = append(matched, item)
+		}
+	}
+
+	return matched
+}
+
+// Match reports whether the item satisfies all conditions of the filter.
+func (f *Filter) Match(item *cache.Item) bool {
+	if f.Feed != "" && !containsFold(item.FeedTitle, f.Feed) && !containsFold(item.FeedURL, f.Feed) {
+		return false
+	}
+
+	if !f.Since.IsZero() {
+		date := Date(item)
+		if date == nil || date.Before(f.Since) {
+			return false
+		}
+	}
+
+	if f.Unread && item.Read {
+		return false
+	}
+
+	if f.Starred && !item.Starred {
+		return false
+	}
+
+	for _, tag := range f.Tags {
+		if !hasCategory(item, tag) {
+			return false
+		}
+	}
+
+	for _, word := range strings.Fields(f.Text) {
+		if !containsFold(item.Title, word) &&
+			!containsFold(item.Description, word) &&
+			!containsFold(item.Content, word) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Date returns the published date of the item, or the updated date if it is not available.
+func Date(item *cache.Item) *time.Time {
+	if item.PublishedParsed != nil {
+		return item.PublishedParsed
+	}
+
+	return item.UpdatedParsed
+}
+
+// ParseSince parses either a relative duration such as "7d" or an absolute date such as "2022-08-01",
+// and returns the point in time it refers to.
+func ParseSince(str string, now time.Time) (time.Time, error) {
+	if t, err := time.ParseInLocation("2006-01-02", str, now.Location()); err == nil {
+		return t, nil
+	}
+
+	if t, err := time.Parse(time.RFC3339, str); err == nil {
+		return t, nil
+	}
+
+	d, err := ParseDuration(str)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return now.Add(-d), nil
+}
+
+// ParseDuration is like time.ParseDuration but also accepts the units
+// "d" (day), "w" (week) and "y" (365 days) such as "7d" or "2w".
+func ParseDuration(str string) (time.Duration, error) {
+	units := map[string]time.Duration{
+		"d": 24 * time.Hour,
+		"w": 7 * 24 * time.Hour,
+		"y": 365 * 24 * time.Hour,
+	}
+
+	for suffix, unit := range units {
+		if !strings.HasSuffix(str, suffix) {
+			continue
+		}
+
+		n, err := strconv.Atoi(strings.TrimSuffix(str, suffix))
+		if err != nil || n < 0 {
+			//nolint:goerr113
+			return 0, fmt.Errorf("invalid duration (%s)", str)
+		}
+
+		return time.Duration(n) * unit, nil
+	}
+
+	d, err := time.ParseDuration(str)
+	if err != nil || d < 0 {
+		//nolint:goerr113
+		return 0, fmt.Errorf("invalid duration (%s)", str)
+	}
+
+	return d, nil
+}
+
+func hasCategory(item *cache.Item, tag string) bool {
+	for _, category := range item.Categories {
+		if strings.EqualFold(category, tag) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func containsFold(s, substr string) bool {
+	return strings.Contains(strings.ToLower(s), strings.ToLower(substr))
+}
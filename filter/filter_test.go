@@ -0,0 +1,86 @@
+package filter_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mmcdole/gofeed"
+	"github.com/sheepla/srss/cache"
+	"github.com/sheepla/srss/filter"
+)
+
+func TestParseDuration(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]time.Duration{
+		"90m": 90 * time.Minute,
+		"12h": 12 * time.Hour,
+		"7d":  7 * 24 * time.Hour,
+		"2w":  14 * 24 * time.Hour,
+		"1y":  365 * 24 * time.Hour,
+	}
+
+	for str, want := range tests {
+		have, err := filter.ParseDuration(str)
+		if err != nil {
+			t.Errorf("an error occurred on `ParseDuration(%q)`: %s", str, err)
+		}
+
+		if have != want {
+			t.Errorf("ParseDuration(%q) = %s, want %s", str, have, want)
+		}
+	}
+
+	for _, str := range []string{"", "d", "-1d", "xyz"} {
+		if _, err := filter.ParseDuration(str); err == nil {
+			t.Errorf("got invalid duration %q but no error occurred", str)
+		}
+	}
+}
+
+//nolint:exhaustruct,exhaustivestruct
+func TestFilter(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	old := now.Add(-30 * 24 * time.Hour)
+	items := []*cache.Item{
+		{
+			Item: &gofeed.Item{
+				Title:           "Generics in Go",
+				Categories:      []string{"Go"},
+				PublishedParsed: &now,
+			},
+			FeedTitle: "The Go Blog",
+		},
+		{
+			Item: &gofeed.Item{
+				Title:           "Rust 1.0",
+				Description:     "Announcing Rust",
+				PublishedParsed: &old,
+			},
+			FeedTitle: "Rust Blog",
+			Read:      true,
+		},
+	}
+
+	tests := []struct {
+		filter filter.Filter
+		want   int
+	}{
+		{filter.Filter{}, 2},
+		{filter.Filter{Feed: "go blog"}, 1},
+		{filter.Filter{Since: now.Add(-7 * 24 * time.Hour)}, 1},
+		{filter.Filter{Unread: true}, 1},
+		{filter.Filter{Tags: []string{"go"}}, 1},
+		{filter.Filter{Text: "announcing rust"}, 1},
+		{filter.Filter{Text: "generics rust"}, 0},
+	}
+
+	for i, test := range tests {
+		test := test
+		if have := len(filter.Apply(items, &test.filter)); have != test.want {
+			t.Errorf("#%d: got %d items, want %d", i, have, test.want)
+		}
+	}
+}
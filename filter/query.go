@@ -0,0 +1,473 @@
+package filter
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/sheepla/srss/cache"
+)
+
+// Matcher reports whether an item should be kept.
+type Matcher interface {
+	Match(item *cache.Item) bool
+}
+
+// Query is a compiled filter expression such as
+//
+//	feed:golang AND unread AND published>7d AND title~"generics"
+//
+// Terms are combined with AND, OR and NOT (in upper case) and grouped with parentheses.
+// Adjacent terms without an operator are combined with AND.
+type Query struct {
+	root node
+}
+
+type node interface {
+	match(item *cache.Item) bool
+}
+
+type (
+	andNode  struct{ left, right node }
+	orNode   struct{ left, right node }
+	notNode  struct{ node node }
+	predNode func(item *cache.Item) bool
+)
+
+func (n andNode) match(item *cache.Item) bool  { return n.left.match(item) && n.right.match(item) }
+func (n orNode) match(item *cache.Item) bool   { return n.left.match(item) || n.right.match(item) }
+func (n notNode) match(item *cache.Item) bool  { return !n.node.match(item) }
+func (n predNode) match(item *cache.Item) bool { return n(item) }
+
+// Match reports whether the item satisfies the query.
+func (q *Query) Match(item *cache.Item) bool {
+	if q.root == nil {
+		return true
+	}
+
+	return q.root.match(item)
+}
+
+// ParseQuery compiles the filter expression.
+// Relative dates in the expression (e.g. "7d") are resolved against now.
+func ParseQuery(str string, now time.Time) (*Query, error) {
+	tokens, err := tokenize(str)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens, pos: 0, now: now}
+	if len(tokens) == 0 {
+		return &Query{root: nil}, nil
+	}
+
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.pos < len(p.tokens) {
+		//nolint:goerr113
+		return nil, fmt.Errorf("unexpected %s in query", p.tokens[p.pos])
+	}
+
+	return &Query{root: root}, nil
+}
+
+type tokenKind int
+
+const (
+	tokWord tokenKind = iota
+	tokPhrase
+	tokTerm
+	tokLParen
+	tokRParen
+)
+
+type token struct {
+	kind  tokenKind
+	field string
+	op    string
+	value string
+}
+
+func (t token) String() string {
+	switch t.kind {
+	case tokLParen:
+		return `"("`
+	case tokRParen:
+		return `")"`
+	case tokTerm:
+		return fmt.Sprintf("%q", t.field+t.op+t.value)
+	case tokWord, tokPhrase:
+		return fmt.Sprintf("%q", t.value)
+	}
+
+	return "token"
+}
+
+// nolint:gochecknoglobals
+var operators = []string{"!=", "!~", ">=", "<=", ":", "~", "=", ">", "<"}
+
+func isOperatorChar(r rune) bool {
+	return strings.ContainsRune(":~=!<>", r)
+}
+
+//nolint:cyclop
+func tokenize(str string) ([]token, error) {
+	runes := []rune(str)
+
+	var tokens []token
+
+	for pos := 0; pos < len(runes); {
+		switch r := runes[pos]; {
+		case unicode.IsSpace(r):
+			pos++
+		case r == '(':
+			tokens = append(tokens, token{kind: tokLParen, field: "", op: "", value: ""})
+			pos++
+		case r == ')':
+			tokens = append(tokens, token{kind: tokRParen, field: "", op: "", value: ""})
+			pos++
+		case r == '"':
+			value, next, err := readQuoted(runes, pos)
+			if err != nil {
+				return nil, err
+			}
+
+			tokens = append(tokens, token{kind: tokPhrase, field: "", op: "", value: value})
+			pos = next
+		default:
+			start := pos
+			for pos < len(runes) && !isDelimiter(runes[pos]) && !isOperatorChar(runes[pos]) {
+				pos++
+			}
+
+			field := string(runes[start:pos])
+			op := readOperator(runes, pos)
+
+			if field == "" || op == "" {
+				for pos < len(runes) && !isDelimiter(runes[pos]) {
+					pos++
+				}
+
+				tokens = append(tokens, token{kind: tokWord, field: "", op: "", value: string(runes[start:pos])})
+
+				continue
+			}
+
+			pos += len([]rune(op))
+
+			var value string
+
+			if pos < len(runes) && runes[pos] == '"' {
+				v, next, err := readQuoted(runes, pos)
+				if err != nil {
+					return nil, err
+				}
+
+				value, pos = v, next
+			} else {
+				begin := pos
+				for pos < len(runes) && !isDelimiter(runes[pos]) {
+					pos++
+				}
+
+				value = string(runes[begin:pos])
+			}
+
+			tokens = append(tokens, token{kind: tokTerm, field: strings.ToLower(field), op: op, value: value})
+		}
+	}
+
+	return tokens, nil
+}
+
+func isDelimiter(r rune) bool {
+	return unicode.IsSpace(r) || r == '(' || r == ')' || r == '"'
+}
+
+func readOperator(runes []rune, pos int) string {
+	rest := string(runes[pos:])
+	for _, op := range operators {
+		if strings.HasPrefix(rest, op) {
+			return op
+		}
+	}
+
+	return ""
+}
+
+func readQuoted(runes []rune, pos int) (string, int, error) {
+	var buf strings.Builder
+
+	for i := pos + 1; i < len(runes); i++ {
+		switch runes[i] {
+		case '\\':
+			if i+1 < len(runes) {
+				i++
+				buf.WriteRune(runes[i])
+			}
+		case '"':
+			return buf.String(), i + 1, nil
+		default:
+			buf.WriteRune(runes[i])
+		}
+	}
+
+	//nolint:goerr113
+	return "", 0, fmt.Errorf("unterminated quoted string in query")
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+	now    time.Time
+}
+
+func (p *parser) peek() (token, bool) {
+	if p.pos >= len(p.tokens) {
+		return token{kind: tokWord, field: "", op: "", value: ""}, false
+	}
+
+	return p.tokens[p.pos], true
+}
+
+func (p *parser) peekKeyword(keyword string) bool {
+	tok, ok := p.peek()
+
+	return ok && tok.kind == tokWord && tok.value == keyword
+}
+
+func (p *parser) parseOr() (node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.peekKeyword("OR") {
+		p.pos++
+
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+
+		left = orNode{left: left, right: right}
+	}
+
+	return left, nil
+}
+
+func (p *parser) parseAnd() (node, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		tok, ok := p.peek()
+		if !ok || tok.kind == tokRParen || p.peekKeyword("OR") {
+			return left, nil
+		}
+
+		if p.peekKeyword("AND") {
+			p.pos++
+		}
+
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+
+		left = andNode{left: left, right: right}
+	}
+}
+
+func (p *parser) parseNot() (node, error) {
+	if p.peekKeyword("NOT") {
+		p.pos++
+
+		n, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+
+		return notNode{node: n}, nil
+	}
+
+	return p.parsePrimary()
+}
+
+func (p *parser) parsePrimary() (node, error) {
+	tok, ok := p.peek()
+	if !ok {
+		//nolint:goerr113
+		return nil, fmt.Errorf("unexpected end of query")
+	}
+
+	p.pos++
+
+	switch tok.kind {
+	case tokLParen:
+		n, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+
+		if next, ok := p.peek(); !ok || next.kind != tokRParen {
+			//nolint:goerr113
+			return nil, fmt.Errorf("missing closing parenthesis in query")
+		}
+
+		p.pos++
+
+		return n, nil
+	case tokRParen:
+		//nolint:goerr113
+		return nil, fmt.Errorf("unexpected %s in query", tok)
+	case tokTerm:
+		return p.compileTerm(tok)
+	case tokPhrase:
+		return textPredicate(tok.value), nil
+	case tokWord:
+		return compileWord(tok.value)
+	}
+
+	//nolint:goerr113
+	return nil, fmt.Errorf("unexpected %s in query", tok)
+}
+
+func compileWord(word string) (node, error) {
+	switch word {
+	case "AND", "OR", "NOT":
+		//nolint:goerr113
+		return nil, fmt.Errorf("unexpected %q in query", word)
+	case "unread":
+		return predNode(func(item *cache.Item) bool { return !item.Read }), nil
+	case "read":
+		return predNode(func(item *cache.Item) bool { return item.Read }), nil
+	case "starred":
+		return predNode(func(item *cache.Item) bool { return item.Starred }), nil
+	}
+
+	return textPredicate(word), nil
+}
+
+func textPredicate(text string) node {
+	return predNode(func(item *cache.Item) bool {
+		return containsFold(item.Title, text) ||
+			containsFold(item.Description, text) ||
+			containsFold(item.Content, text)
+	})
+}
+
+// stringFields returns the values of the item which are compared by the field in the query.
+//
+//nolint:gochecknoglobals
+var stringFields = map[string]func(item *cache.Item) []string{
+	"title": func(item *cache.Item) []string { return []string{item.Title} },
+	"author": func(item *cache.Item) []string {
+		if item.Author == nil {
+			return nil
+		}
+
+		return []string{item.Author.Name}
+	},
+	"feed":     func(item *cache.Item) []string { return []string{item.FeedTitle, item.FeedURL} },
+	"link":     func(item *cache.Item) []string { return []string{item.Link} },
+	"category": func(item *cache.Item) []string { return item.Categories },
+	"tag":      func(item *cache.Item) []string { return item.Categories },
+	"content":  func(item *cache.Item) []string { return []string{item.Description, item.Content} },
+	"text": func(item *cache.Item) []string {
+		return []string{item.Title, item.Description, item.Content}
+	},
+}
+
+// nolint:gochecknoglobals
+var dateFields = map[string]func(item *cache.Item) *time.Time{
+	"published": Date,
+	"date":      Date,
+	"updated":   func(item *cache.Item) *time.Time { return item.UpdatedParsed },
+}
+
+func (p *parser) compileTerm(tok token) (node, error) {
+	if getter, ok := stringFields[tok.field]; ok {
+		return compileStringTerm(getter, tok)
+	}
+
+	if getter, ok := dateFields[tok.field]; ok {
+		return p.compileDateTerm(getter, tok)
+	}
+
+	//nolint:goerr113
+	return nil, fmt.Errorf("unknown field %q in query", tok.field)
+}
+
+func compileStringTerm(getter func(item *cache.Item) []string, tok token) (node, error) {
+	var match func(value string) bool
+
+	switch tok.op {
+	case ":":
+		match = func(value string) bool { return containsFold(value, tok.value) }
+	case "=", "!=":
+		match = func(value string) bool { return strings.EqualFold(value, tok.value) }
+	case "~", "!~":
+		re, err := regexp.Compile("(?i)" + tok.value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regular expression in %s: %w", tok, err)
+		}
+
+		match = re.MatchString
+	default:
+		//nolint:goerr113
+		return nil, fmt.Errorf("operator %q cannot be used with the field %q", tok.op, tok.field)
+	}
+
+	var n node = predNode(func(item *cache.Item) bool {
+		for _, value := range getter(item) {
+			if match(value) {
+				return true
+			}
+		}
+
+		return false
+	})
+
+	if strings.HasPrefix(tok.op, "!") {
+		n = notNode{node: n}
+	}
+
+	return n, nil
+}
+
+func (p *parser) compileDateTerm(getter func(item *cache.Item) *time.Time, tok token) (node, error) {
+	point, err := ParseSince(tok.value, p.now)
+	if err != nil {
+		return nil, fmt.Errorf("invalid date in %s: %w", tok, err)
+	}
+
+	var cmp func(t time.Time) bool
+
+	switch tok.op {
+	case ">":
+		cmp = func(t time.Time) bool { return t.After(point) }
+	case ">=":
+		cmp = func(t time.Time) bool { return !t.Before(point) }
+	case "<":
+		cmp = func(t time.Time) bool { return t.Before(point) }
+	case "<=":
+		cmp = func(t time.Time) bool { return !t.After(point) }
+	default:
+		//nolint:goerr113
+		return nil, fmt.Errorf("operator %q cannot be used with the field %q", tok.op, tok.field)
+	}
+
+	return predNode(func(item *cache.Item) bool {
+		t := getter(item)
+
+		return t != nil && cmp(*t)
+	}), nil
+}
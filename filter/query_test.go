@@ -0,0 +1,78 @@
+package filter_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mmcdole/gofeed"
+	"github.com/sheepla/srss/cache"
+	"github.com/sheepla/srss/filter"
+)
+
+//nolint:exhaustruct,exhaustivestruct,funlen
+func TestParseQuery(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	old := now.Add(-30 * 24 * time.Hour)
+	items := []*cache.Item{
+		{
+			Item: &gofeed.Item{
+				Title:           "Generics in Go",
+				Author:          &gofeed.Person{Name: "Ian"},
+				Categories:      []string{"Go"},
+				PublishedParsed: &now,
+			},
+			FeedTitle: "The Go Blog",
+			FeedURL:   "https://go.dev/blog/feed.atom",
+		},
+		{
+			Item: &gofeed.Item{
+				Title:           "Rust 1.0",
+				Description:     "Announcing Rust",
+				PublishedParsed: &old,
+			},
+			FeedTitle: "Rust Blog",
+			Read:      true,
+		},
+	}
+
+	tests := map[string]int{
+		``:                          2,
+		`unread`:                    1,
+		`NOT unread`:                1,
+		`feed:golang`:               0,
+		`feed:go.dev`:               1,
+		`feed:"go blog" AND unread`: 1,
+		`feed:go OR read`:           2,
+		`published>7d`:              1,
+		`published<2000-01-01`:      0,
+		`title~"^gen.*go$"`:         1,
+		`title!~gen`:                1,
+		`author=ian`:                1,
+		`tag:go`:                    1,
+		`announcing`:                1,
+		`"Rust 1.0"`:                1,
+		`(feed:go OR feed:rust) AND NOT published>7d`:                  1,
+		`feed:golang AND unread AND published>7d AND title~"generics"`: 0,
+	}
+
+	for str, want := range tests {
+		query, err := filter.ParseQuery(str, now)
+		if err != nil {
+			t.Errorf("an error occurred on `ParseQuery(%q)`: %s", str, err)
+
+			continue
+		}
+
+		if have := len(filter.Apply(items, query)); have != want {
+			t.Errorf("%q: got %d items, want %d", str, have, want)
+		}
+	}
+
+	for _, str := range []string{`(unread`, `unread)`, `AND`, `foo:bar`, `title~"(`, `published:7d`, `"open`} {
+		if _, err := filter.ParseQuery(str, now); err == nil {
+			t.Errorf("got invalid query %q but no error occurred", str)
+		}
+	}
+}
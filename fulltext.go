@@ -0,0 +1,150 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sheepla/srss/cache"
+	"github.com/sheepla/srss/readability"
+	"github.com/sheepla/srss/redact"
+)
+
+const (
+	fullTextConcurrency = 4
+	fullTextTimeout     = 30 * time.Second
+	// maxPageSize limits the size of the pages read to extract the articles.
+	maxPageSize = 10 << 20
+)
+
+// fetchFullTexts fills the full texts of the items of the feeds with full_text in the config.
+// The full texts in the cache are reused and only the new items are fetched.
+// Failures are reported and do not stop the update since the items still have the summaries.
+// The remaining full texts are skipped when the context is done. The number of the fetched ones is printed to w,
+// and the failures to errw.
+func fetchFullTexts(
+	ctx context.Context, fetchers *feedFetchers, urls []string, items [][]*cache.Item, w, errw io.Writer,
+) error {
+	targets := make([]*cache.Item, 0)
+
+	for i, url := range urls {
+		if feed := fetchers.conf.Feed(url); feed != nil && feed.FullText {
+			targets = append(targets, items[i]...)
+		}
+	}
+
+	if len(targets) == 0 {
+		return nil
+	}
+
+	cached, err := loadItems()
+	if err != nil {
+		return err
+	}
+
+	fullTexts := make(map[string]string, len(cached))
+	for _, item := range cached {
+		if item.FullText != "" {
+			fullTexts[item.Key()] = item.FullText
+		}
+	}
+
+	missing := make([]*cache.Item, 0, len(targets))
+
+	for _, item := range targets {
+		if text, ok := fullTexts[item.Key()]; ok {
+			item.FullText = text
+		} else if item.Link != "" {
+			missing = append(missing, item)
+		}
+	}
+
+	queue := make(chan *cache.Item)
+
+	var (
+		wg      sync.WaitGroup
+		fetched int32
+	)
+
+	for w := 0; w < fullTextConcurrency; w++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for item := range queue {
+				if ctx.Err() != nil {
+					continue
+				}
+
+				client, err := fetchers.client(item.FeedURL)
+				if err != nil {
+					fmt.Fprintf(errw, "Failed to fetch the full text: %s\n", err)
+
+					continue
+				}
+
+				text, err := fetchFullText(ctx, client, item.Link)
+				if err != nil {
+					fmt.Fprintf(errw, "Failed to fetch the full text: %s\n", err)
+
+					continue
+				}
+
+				item.FullText = text
+
+				atomic.AddInt32(&fetched, 1)
+			}
+		}()
+	}
+
+	for _, item := range missing {
+		queue <- item
+	}
+
+	close(queue)
+	wg.Wait()
+
+	if fetched != 0 {
+		fmt.Fprintf(w, "Fetched the full text of %d items\n", fetched)
+	}
+
+	return nil
+}
+
+// fetchFullText downloads the page and extracts the article.
+func fetchFullText(ctx context.Context, client *http.Client, url string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, fullTextTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", redact.URL(url), err)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch the page: %w", redact.Error(err))
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("%s: %s", redact.URL(url), resp.Status) //nolint:goerr113
+	}
+
+	if ct := resp.Header.Get("Content-Type"); ct != "" && !strings.Contains(ct, "html") {
+		return "", fmt.Errorf("%s: not an HTML page (%s)", redact.URL(url), ct) //nolint:goerr113
+	}
+
+	text, err := readability.Extract(io.LimitReader(resp.Body, maxPageSize))
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", redact.URL(url), err)
+	}
+
+	return text, nil
+}
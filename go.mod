@@ -3,6 +3,7 @@ module github.com/sheepla/srss
 go 1.18
 
 require (
+	github.com/PuerkitoBio/goquery v1.5.1
 	github.com/charmbracelet/bubbles v0.10.3
 	github.com/charmbracelet/bubbletea v0.19.3
 	github.com/charmbracelet/lipgloss v0.6.0
@@ -11,15 +12,16 @@ require (
 	github.com/ktr0731/go-fuzzyfinder v0.6.0
 	github.com/mattn/go-runewidth v0.0.13
 	github.com/mmcdole/gofeed v1.1.3
-	github.com/pkg/errors v0.9.1
 	github.com/toqueteos/webbrowser v1.2.0
 	github.com/urfave/cli/v2 v2.23.7
+	go.etcd.io/bbolt v1.3.7
 	golang.org/x/net v0.0.0-20200301022130-244492dfa37a
 )
 
 require (
-	github.com/PuerkitoBio/goquery v1.5.1 // indirect
 	github.com/andybalholm/cascadia v1.1.0 // indirect
+	github.com/atotto/clipboard v0.1.4 // indirect
+	github.com/charmbracelet/harmonica v0.1.0 // indirect
 	github.com/containerd/console v1.0.2 // indirect
 	github.com/cpuguy83/go-md2man/v2 v2.0.2 // indirect
 	github.com/gdamore/encoding v1.0.0 // indirect
@@ -34,10 +36,11 @@ require (
 	github.com/muesli/reflow v0.3.0 // indirect
 	github.com/muesli/termenv v0.11.1-0.20220204035834-5ac8409525e0 // indirect
 	github.com/nsf/termbox-go v0.0.0-20201124104050-ed494de23a00 // indirect
+	github.com/pkg/errors v0.9.1 // indirect
 	github.com/rivo/uniseg v0.2.0 // indirect
 	github.com/russross/blackfriday/v2 v2.1.0 // indirect
 	github.com/xrash/smetrics v0.0.0-20201216005158-039620a65673 // indirect
-	golang.org/x/sys v0.0.0-20210630005230-0f9fa26af87c // indirect
+	golang.org/x/sys v0.4.0 // indirect
 	golang.org/x/term v0.0.0-20210422114643-f5beecf764ed // indirect
 	golang.org/x/text v0.3.7 // indirect
 )
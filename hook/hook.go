@@ -0,0 +1,139 @@
+// Package hook runs the commands of the user with the new items of the feeds, e.g. to send notifications.
+package hook
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/sheepla/srss/cache"
+	"github.com/sheepla/srss/output"
+	"github.com/sheepla/srss/redact"
+)
+
+// Input is how the items are passed to the command.
+type Input string
+
+const (
+	// InputJSON runs the command once with the items in JSON lines on the stdin, as `srss cat --format json` prints.
+	InputJSON Input = "json"
+	// InputEnv runs the command for each item with the fields of the item in the environment variables.
+	InputEnv Input = "env"
+)
+
+// envPrefix is the prefix of the environment variables of the items.
+const envPrefix = "SRSS_"
+
+var (
+	ErrEmptyCommand = errors.New("the command is empty")
+	ErrUnknownInput = errors.New("unknown input")
+)
+
+// Hook is a command run with the items.
+type Hook struct {
+	// Args are the command and its arguments, which are run without a shell.
+	Args  []string
+	Input Input
+	// Output receives the stdout and stderr of the command.
+	Output io.Writer
+}
+
+// New returns the hook running the command, whose arguments are separated by spaces.
+// The input is InputJSON if empty.
+func New(command string, input Input, output io.Writer) (*Hook, error) {
+	args := strings.Fields(command)
+	if len(args) == 0 {
+		return nil, ErrEmptyCommand
+	}
+
+	switch input {
+	case "":
+		input = InputJSON
+	case InputJSON, InputEnv:
+	default:
+		return nil, fmt.Errorf("%w (%s), must be %s or %s", ErrUnknownInput, input, InputJSON, InputEnv)
+	}
+
+	return &Hook{Args: args, Input: input, Output: output}, nil
+}
+
+// Run runs the command with the items. The command is killed when the context is done.
+// With InputEnv, the items after the first failure are not passed to the command.
+func (h *Hook) Run(ctx context.Context, items []*cache.Item) error {
+	if len(items) == 0 {
+		return nil
+	}
+
+	if h.Input == InputEnv {
+		for _, item := range items {
+			if err := h.run(ctx, nil, Env(item)); err != nil {
+				return fmt.Errorf("failed to run the hook for the item (%s): %w", item.Title, err)
+			}
+		}
+
+		return nil
+	}
+
+	var buf bytes.Buffer
+	if err := output.Write(&buf, items, output.FormatJSON); err != nil {
+		return err
+	}
+
+	return h.run(ctx, &buf, nil)
+}
+
+func (h *Hook) run(ctx context.Context, stdin io.Reader, env []string) error {
+	//nolint:gosec
+	cmd := exec.CommandContext(ctx, h.Args[0], h.Args[1:]...)
+	cmd.Stdin = stdin
+	cmd.Stdout = h.Output
+	cmd.Stderr = h.Output
+	cmd.Env = append(os.Environ(), env...)
+
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return fmt.Errorf("%s was stopped: %w", h.Args[0], ctx.Err())
+		}
+
+		return fmt.Errorf("failed to run %s: %w", h.Args[0], err)
+	}
+
+	return nil
+}
+
+// Env returns the environment variables of the fields of the item, e.g. SRSS_TITLE=Go 1.18 is released.
+// The credentials in the URL of the feed are masked.
+func Env(item *cache.Item) []string {
+	author := ""
+	if item.Author != nil {
+		author = item.Author.Name
+	}
+
+	published := ""
+	if item.PublishedParsed != nil {
+		published = item.PublishedParsed.Format(time.RFC3339)
+	}
+
+	fields := []struct{ name, value string }{
+		{"ID", item.ID()},
+		{"FEED", item.FeedTitle},
+		{"FEED_URL", redact.URL(item.FeedURL)},
+		{"TITLE", item.Title},
+		{"LINK", item.Link},
+		{"AUTHOR", author},
+		{"PUBLISHED", published},
+	}
+
+	env := make([]string, len(fields))
+	for i, field := range fields {
+		env[i] = envPrefix + field.name + "=" + field.value
+	}
+
+	return env
+}
@@ -0,0 +1,136 @@
+package hook_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mmcdole/gofeed"
+	"github.com/sheepla/srss/cache"
+	"github.com/sheepla/srss/hook"
+)
+
+//nolint:gochecknoglobals,exhaustruct,exhaustivestruct
+var items = []*cache.Item{
+	{
+		Item:      &gofeed.Item{Title: "first", GUID: "first", Link: "https://example.com/first"},
+		FeedTitle: "Example",
+		FeedURL:   "https://example.com/feed.xml",
+	},
+	{
+		Item:      &gofeed.Item{Title: "second", GUID: "second", Link: "https://example.com/second"},
+		FeedTitle: "Example",
+		FeedURL:   "https://example.com/feed.xml",
+	},
+}
+
+func requireShell(t *testing.T) {
+	t.Helper()
+
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh is not available")
+	}
+}
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	h, err := hook.New("notify-send  --app-name srss", "", io.Discard)
+	if err != nil {
+		t.Fatalf("an error occurred on `New()`: %s", err)
+	}
+
+	if strings.Join(h.Args, ",") != "notify-send,--app-name,srss" || h.Input != hook.InputJSON {
+		t.Errorf("unexpected hook: %+v", h)
+	}
+
+	if _, err := hook.New(" ", hook.InputEnv, io.Discard); !errors.Is(err, hook.ErrEmptyCommand) {
+		t.Errorf("got %v, want ErrEmptyCommand", err)
+	}
+
+	if _, err := hook.New("true", "stdin", io.Discard); !errors.Is(err, hook.ErrUnknownInput) {
+		t.Errorf("got %v, want ErrUnknownInput", err)
+	}
+}
+
+func TestRunJSON(t *testing.T) {
+	t.Parallel()
+	requireShell(t)
+
+	out := filepath.Join(t.TempDir(), "out")
+	h := &hook.Hook{Args: []string{"sh", "-c", "cat > " + out}, Input: hook.InputJSON, Output: io.Discard}
+
+	if err := h.Run(context.Background(), items); err != nil {
+		t.Fatalf("an error occurred on `Run()`: %s", err)
+	}
+
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatalf("the hook wrote nothing: %s", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 2 || !strings.Contains(lines[0], `"title":"first"`) || !strings.Contains(lines[1], `"title":"second"`) {
+		t.Errorf("unexpected input of the hook: %q", data)
+	}
+}
+
+func TestRunEnv(t *testing.T) {
+	t.Parallel()
+	requireShell(t)
+
+	out := filepath.Join(t.TempDir(), "out")
+	h := &hook.Hook{
+		Args:   []string{"sh", "-c", `echo "$SRSS_FEED: $SRSS_TITLE $SRSS_LINK" >> ` + out},
+		Input:  hook.InputEnv,
+		Output: io.Discard,
+	}
+
+	if err := h.Run(context.Background(), items); err != nil {
+		t.Fatalf("an error occurred on `Run()`: %s", err)
+	}
+
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatalf("the hook wrote nothing: %s", err)
+	}
+
+	want := "Example: first https://example.com/first\nExample: second https://example.com/second\n"
+	if string(data) != want {
+		t.Errorf("got %q, want %q", data, want)
+	}
+}
+
+func TestRunFailure(t *testing.T) {
+	t.Parallel()
+	requireShell(t)
+
+	h := &hook.Hook{Args: []string{"sh", "-c", "exit 3"}, Input: hook.InputEnv, Output: io.Discard}
+
+	if err := h.Run(context.Background(), items[:1]); err == nil {
+		t.Error("the failure of the hook is not returned")
+	}
+
+	// The command is not run without items.
+	if err := h.Run(context.Background(), nil); err != nil {
+		t.Errorf("an error occurred without items: %s", err)
+	}
+}
+
+//nolint:exhaustruct,exhaustivestruct
+func TestEnv(t *testing.T) {
+	t.Parallel()
+
+	item := &cache.Item{Item: &gofeed.Item{Title: "first", GUID: "first"}, FeedURL: "https://example.com/feed.xml?token=secret"}
+
+	for _, env := range hook.Env(item) {
+		if strings.HasPrefix(env, "SRSS_FEED_URL=") && env != "SRSS_FEED_URL=https://example.com/feed.xml?token=xxxxx" {
+			t.Errorf("the token of the feed is not masked: %s", env)
+		}
+	}
+}
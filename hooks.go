@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/sheepla/srss/cache"
+	"github.com/sheepla/srss/config"
+	"github.com/sheepla/srss/fetch"
+	"github.com/sheepla/srss/filter"
+	"github.com/sheepla/srss/hook"
+)
+
+// hookTimeout limits the time of each hook so that a stuck command does not block the updates.
+const hookTimeout = time.Minute
+
+// runHooks runs the hooks in the config with the new items of the fetched feeds which match them,
+// and returns the failures of the hooks. The added items are at the indexes of the results.
+// The feeds which were not in the cache before the update are excluded so that adding a feed
+// does not pass all its items to the hooks. The output of the hooks is printed to w.
+func runHooks(
+	ctx context.Context, conf *config.Config, results []*fetch.Result, added [][]*cache.Item, cached map[string]*cache.Feed,
+	w io.Writer,
+) []error {
+	if len(conf.Hooks) == 0 {
+		return nil
+	}
+
+	var items []*cache.Item
+
+	for i, result := range results {
+		if cached[result.URL] != nil {
+			items = append(items, added[i]...)
+		}
+	}
+
+	if len(items) == 0 {
+		return nil
+	}
+
+	var failures []error
+
+	for _, hc := range conf.Hooks {
+		if err := runHook(ctx, hc, items, w); err != nil {
+			failures = append(failures, err)
+		}
+	}
+
+	return failures
+}
+
+func runHook(ctx context.Context, hc config.HookConfig, items []*cache.Item, w io.Writer) error {
+	//nolint:exhaustruct,exhaustivestruct
+	matched := filter.Apply(items, &filter.Filter{Feed: hc.Feed})
+
+	if hc.Query != "" {
+		query, err := filter.ParseQuery(hc.Query, time.Now())
+		if err != nil {
+			return fmt.Errorf("invalid query of the hook (%s): %w", hc.Command, err)
+		}
+
+		matched = filter.Apply(matched, query)
+	}
+
+	h, err := hook.New(hc.Command, hook.Input(hc.Input), w)
+	if err != nil {
+		return fmt.Errorf("invalid hook (%s): %w", hc.Command, err)
+	}
+
+	h.Args[0] = expandHome(h.Args[0])
+
+	ctx, cancel := context.WithTimeout(ctx, hookTimeout)
+	defer cancel()
+
+	return h.Run(ctx, matched)
+}
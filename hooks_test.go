@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/mmcdole/gofeed"
+	"github.com/sheepla/srss/cache"
+	"github.com/sheepla/srss/config"
+	"github.com/sheepla/srss/fetch"
+)
+
+//nolint:paralleltest,exhaustruct,exhaustivestruct
+func TestRunHooksAfterPrune(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh is not available")
+	}
+
+	dir := t.TempDir()
+	out := filepath.Join(dir, "hook.log")
+
+	store, err := cache.OpenPath(filepath.Join(dir, "cache.db"))
+	if err != nil {
+		t.Fatalf("an error occurred on `OpenPath()`: %s", err)
+	}
+	defer store.Close()
+
+	url := "https://example.com/feed.xml"
+	feed := &gofeed.Feed{Title: "Example", Items: []*gofeed.Item{{Title: "old", GUID: "old"}}}
+
+	if _, err := store.ReplaceItems(url, cache.NewItems(url, feed)); err != nil {
+		t.Fatalf("an error occurred on `ReplaceItems()`: %s", err)
+	}
+
+	items, err := store.Items()
+	if err != nil {
+		t.Fatalf("an error occurred on `Items()`: %s", err)
+	}
+
+	// Prune the item which is still in the feed, then update the feed again.
+	if err := store.DeleteItems(items[0].Key()); err != nil {
+		t.Fatalf("an error occurred on `DeleteItems()`: %s", err)
+	}
+
+	added, err := store.ReplaceItems(url, cache.NewItems(url, feed))
+	if err != nil {
+		t.Fatalf("an error occurred on `ReplaceItems()`: %s", err)
+	}
+
+	conf := &config.Config{Hooks: []config.HookConfig{{Command: "sh -c cat>" + out}}}
+	results := []*fetch.Result{{URL: url, Feed: feed}}
+	cached := map[string]*cache.Feed{url: {URL: url}}
+
+	if failures := runHooks(context.Background(), conf, results, [][]*cache.Item{added}, cached, io.Discard); len(failures) != 0 {
+		t.Fatalf("the hook failed: %v", failures)
+	}
+
+	if _, err := os.Stat(out); err == nil {
+		t.Error("the hook ran for the pruned item")
+	}
+
+	// A new item still runs the hook.
+	feed.Items = append(feed.Items, &gofeed.Item{Title: "new", GUID: "new"})
+
+	if added, err = store.ReplaceItems(url, cache.NewItems(url, feed)); err != nil {
+		t.Fatalf("an error occurred on `ReplaceItems()`: %s", err)
+	}
+
+	if failures := runHooks(context.Background(), conf, results, [][]*cache.Item{added}, cached, io.Discard); len(failures) != 0 {
+		t.Fatalf("the hook failed: %v", failures)
+	}
+
+	if _, err := os.Stat(out); err != nil {
+		t.Errorf("the hook did not run for the new item: %s", err)
+	}
+}
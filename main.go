@@ -1,15 +1,27 @@
 package main
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"io"
+	"net/http"
 	"os"
+	"os/signal"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/ktr0731/go-fuzzyfinder"
-	"github.com/mmcdole/gofeed"
 	"github.com/sheepla/srss/cache"
+	"github.com/sheepla/srss/config"
+	"github.com/sheepla/srss/fetch"
+	"github.com/sheepla/srss/filter"
 	"github.com/sheepla/srss/opml"
+	"github.com/sheepla/srss/output"
+	"github.com/sheepla/srss/redact"
+	"github.com/sheepla/srss/schedule"
+	"github.com/sheepla/srss/search"
 	"github.com/sheepla/srss/ui"
 	"github.com/sheepla/srss/urlentry"
 	"github.com/urfave/cli/v2"
@@ -35,6 +47,11 @@ const (
 	exitCodeErrEditor
 	exitCodeErrBrowser
 	exitCodeErrCache
+	exitCodeErrOutput
+	exitCodeErrConfig
+	exitCodeErrPlayer
+	exitCodeErrDownload
+	exitCodeErrHook
 )
 
 const asciiArt = `
@@ -67,7 +84,17 @@ func initApp() *cli.App {
 		Suggest:              false,
 		EnableBashCompletion: true,
 		Before: func(ctx *cli.Context) error {
-			return nil
+			conf, err := config.Load()
+			if err != nil {
+				return cli.Exit(
+					fmt.Sprintf("failed to load config: %s", err),
+					int(exitCodeErrConfig),
+				)
+			}
+
+			ui.SetMediaPlayer(conf.Player)
+
+			return applyTimeConfig(&conf.Time)
 		},
 		Action: func(ctx *cli.Context) error {
 			if ctx.NArg() == 0 {
@@ -105,13 +132,31 @@ func initApp() *cli.App {
 				Name:    "tui",
 				Aliases: []string{"t"},
 				Usage:   "View items in the feed with built-in pager",
-				Action:  runTUICommand,
+				Flags: append(append(queryFlags(), viewFlags()...), &cli.BoolFlag{
+					Name:  "starred",
+					Usage: "View only starred items",
+				}),
+				Action: runTUICommand,
+			},
+			{
+				Name:      "open",
+				Aliases:   []string{"o"},
+				Usage:     "Open feed URL on your browser",
+				ArgsUsage: "[id...]",
+				Flags: append([]cli.Flag{
+					&cli.BoolFlag{
+						Name:    "print",
+						Aliases: []string{"p"},
+						Usage:   "Print the URLs instead of opening them",
+					},
+				}, viewFlags()...),
+				Action: runOpenCommand,
 			},
 			{
-				Name:    "open",
-				Aliases: []string{"o"},
-				Usage:   "Open feed URL on your browser",
-				Action:  runOpenCommand,
+				Name:      "show",
+				Usage:     "Print the content of the items with the IDs",
+				ArgsUsage: "<id>...",
+				Action:    runShowCommand,
 			},
 			{
 				Name:    "import",
@@ -126,16 +171,138 @@ func initApp() *cli.App {
 				},
 				Action: runImportCommand,
 			},
+			{
+				Name:    "cat",
+				Aliases: []string{"c"},
+				Usage:   "Print items in the cache to stdout",
+				Flags:   catFlags(),
+				Action:  runCatCommand,
+			},
+			{
+				Name:      "search",
+				Aliases:   []string{"s"},
+				Usage:     "Search the full text of items in the cache",
+				ArgsUsage: "[terms...]",
+				Flags:     searchFlags(),
+				Action:    runSearchCommand,
+			},
+			{
+				Name:      "star",
+				Usage:     "Star items so that they are kept in the cache permanently",
+				ArgsUsage: "[id...]",
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:    "unstar",
+						Aliases: []string{"u"},
+						Usage:   "Unstar the selected items instead",
+					},
+				},
+				Action: runStarCommand,
+			},
+			{
+				Name:      "mark-read",
+				Aliases:   []string{"m"},
+				Usage:     "Mark items as read",
+				ArgsUsage: "[id...]",
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:    "unread",
+						Aliases: []string{"u"},
+						Usage:   "Mark the items as unread instead",
+					},
+				},
+				Action: runMarkReadCommand,
+			},
+			cacheCommand(),
+			downloadCommand(),
+			daemonCommand(),
+			listCommand(),
 			{
 				Name:    "update",
 				Aliases: []string{"u"},
 				Usage:   "Fetch the latest feeds and update the cache",
-				Action:  runUpdateCommand,
+				Description: "Feeds which are not due yet are skipped: they are fetched at most at the interval in the config file, " +
+					"or the longer one suggested by the feed (<ttl>, sy:updatePeriod) or the server (Cache-Control: max-age), " +
+					"and not in the <skipHours> and <skipDays> of the feed",
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:    "force",
+						Aliases: []string{"f"},
+						Usage:   "Fetch all feeds even if they are not due yet",
+					},
+					&cli.StringFlag{
+						Name:  "deadline",
+						Usage: "Give up the feeds not fetched in the duration, overriding the config file (e.g. 2m)",
+					},
+					&cli.BoolFlag{
+						Name:    "quiet",
+						Aliases: []string{"q"},
+						Usage:   "Print only the errors and the changes of the feeds",
+					},
+					&cli.BoolFlag{
+						Name:  "verbose",
+						Usage: "Print each feed when it starts and finishes, and the feeds which are not due yet",
+					},
+					&cli.BoolFlag{
+						Name:  "list-new",
+						Usage: "List the titles of the new items after the summary",
+					},
+					&cli.BoolFlag{
+						Name:  "json",
+						Usage: "Print the new items of each feed as JSON lines instead of the progress and the summary",
+					},
+				},
+				Action: runUpdateCommand,
 			},
 		},
 	}
 }
 
+// applyTimeConfig sets how the dates are displayed in the UI.
+func applyTimeConfig(conf *config.TimeConfig) error {
+	//nolint:exhaustruct,exhaustivestruct
+	format := &ui.TimeFormat{
+		Layout: conf.Layout,
+		Locale: conf.Locale,
+	}
+
+	switch conf.Format {
+	case "", "relative":
+	case "absolute":
+		format.Absolute = true
+	default:
+		return cli.Exit(
+			fmt.Sprintf("invalid time format in config (%s), must be relative or absolute", conf.Format),
+			int(exitCodeErrConfig),
+		)
+	}
+
+	if format.Locale == "" {
+		format.Locale = ui.DefaultLocale()
+	} else if !contains(ui.Locales(), format.Locale) {
+		return cli.Exit(
+			fmt.Sprintf("unsupported locale in config (%s), must be one of %s", conf.Locale, strings.Join(ui.Locales(), ", ")),
+			int(exitCodeErrConfig),
+		)
+	}
+
+	if conf.Timezone != "" {
+		loc, err := time.LoadLocation(conf.Timezone)
+		if err != nil {
+			return cli.Exit(
+				fmt.Sprintf("invalid time zone in config (%s): %s", conf.Timezone, err),
+				int(exitCodeErrConfig),
+			)
+		}
+
+		format.Location = loc
+	}
+
+	ui.SetTimeFormat(format)
+
+	return nil
+}
+
 func runAddCommand(ctx *cli.Context) error {
 	if ctx.NArg() != 1 {
 		return cli.Exit(
@@ -189,16 +356,56 @@ func runEditCommand(ctx *cli.Context) error {
 }
 
 func runTUICommand(ctx *cli.Context) error {
-	items, err := cache.Import()
+	items, err := loadItemsOrSetup()
+	if err != nil {
+		return err
+	}
+
+	conf, err := config.Load()
 	if err != nil {
 		return cli.Exit(
-			fmt.Sprintf("failed to load cache: %s", err),
-			int(exitCodeErrCache),
+			fmt.Sprintf("failed to load config: %s", err),
+			int(exitCodeErrConfig),
 		)
 	}
 
+	query, err := queryFromFlags(ctx, conf)
+	if err != nil {
+		return err
+	}
+
+	if ctx.Bool("starred") {
+		//nolint:exhaustruct,exhaustivestruct
+		items = filter.Apply(items, &filter.Filter{Starred: true})
+	}
+
+	view, err := viewFromFlags(ctx)
+	if err != nil {
+		return err
+	}
+
+	// The folders are shown even without saved queries so that the search and the starred items are reachable.
+	if query != nil || ctx.Bool("starred") {
+		if query != nil {
+			items = filter.Apply(items, query)
+		}
+
+		if err := browseItems(items, view); err != nil {
+			return err
+		}
+
+		return cli.Exit("quit", int(exitCodeOK))
+	}
+
 	for {
-		idx, err := ui.FindItem(items)
+		folders, err := smartFolders(items, conf)
+		if err != nil {
+			return err
+		}
+
+		folders = append(folders, ui.Folder{Name: "Search articles...", Query: "", Items: nil, Search: true})
+
+		idx, err := ui.FindFolder(folders)
 		if err != nil {
 			if errors.Is(fuzzyfinder.ErrAbort, err) {
 				return cli.Exit(
@@ -213,7 +420,52 @@ func runTUICommand(ctx *cli.Context) error {
 			)
 		}
 
-		pager, err := ui.NewPager(items[idx])
+		if folders[idx].Search {
+			index, err := loadSearchIndex(items)
+			if err != nil {
+				return err
+			}
+
+			if err := searchInteractive(index, items); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		if err := browseItems(folders[idx].Items, view); err != nil {
+			return err
+		}
+	}
+}
+
+// browseItems lets the user select items and read them in the pager until the finder is aborted.
+// The items are arranged by the view unless it is nil.
+func browseItems(items []*cache.Item, view *ui.View) error {
+	for {
+		item, err := ui.FindItem(items, view)
+		if err != nil {
+			if errors.Is(fuzzyfinder.ErrAbort, err) {
+				return nil
+			}
+
+			return cli.Exit(
+				fmt.Sprintf("an error occurred on fuzzyfinder: %s", err),
+				int(exitCodeErrFuzzyFinder),
+			)
+		}
+
+		if err := viewItem(item); err != nil {
+			return err
+		}
+	}
+}
+
+// viewItem shows the item in the pager, and plays the enclosure selected in the pager
+// with the media player before showing the pager again.
+func viewItem(item *cache.Item) error {
+	for {
+		pager, err := ui.NewPager(item)
 		if err != nil {
 			return cli.Exit(
 				fmt.Sprintf("failed to init pager: %s", err),
@@ -227,30 +479,158 @@ func runTUICommand(ctx *cli.Context) error {
 				int(exitCodeErrPager),
 			)
 		}
+
+		if !item.Read {
+			if err := markRead(item); err != nil {
+				return err
+			}
+		}
+
+		if pager.Starred() != item.Starred {
+			if err := setStarred(pager.Starred(), item); err != nil {
+				return err
+			}
+		}
+
+		if pager.Media() == "" {
+			return nil
+		}
+
+		if err := ui.PlayMedia(pager.Media()); err != nil {
+			return cli.Exit(
+				fmt.Sprintf("failed to play media: %s", err),
+				int(exitCodeErrPlayer),
+			)
+		}
 	}
 }
 
-func runOpenCommand(ctx *cli.Context) error {
-	items, err := cache.Import()
+// smartFolders returns the folders of all items and the starred items followed by the folders of the saved queries.
+//
+//nolint:exhaustruct,exhaustivestruct
+func smartFolders(items []*cache.Item, conf *config.Config) ([]ui.Folder, error) {
+	folders := []ui.Folder{
+		{Name: "All items", Query: "", Items: items, Search: false},
+		{Name: "Starred", Query: "starred", Items: filter.Apply(items, &filter.Filter{Starred: true}), Search: false},
+	}
+
+	for _, saved := range conf.Queries {
+		query, err := filter.ParseQuery(saved.Query, time.Now())
+		if err != nil {
+			return nil, cli.Exit(
+				fmt.Sprintf("invalid saved query (%s): %s", saved.Name, err),
+				int(exitCodeErrConfig),
+			)
+		}
+
+		folders = append(folders, ui.Folder{
+			Name:   saved.Name,
+			Query:  saved.Query,
+			Items:  filter.Apply(items, query),
+			Search: false,
+		})
+	}
+
+	return folders, nil
+}
+
+// queryFromFlags compiles the query given by the --query or --folder flag.
+// It returns nil if neither of them is specified.
+func queryFromFlags(ctx *cli.Context, conf *config.Config) (*filter.Query, error) {
+	str := strings.TrimSpace(ctx.String("query"))
+
+	if name := strings.TrimSpace(ctx.String("folder")); name != "" {
+		if str != "" {
+			return nil, cli.Exit(
+				"cannot specify both --query and --folder",
+				int(exitCodeErrArgs),
+			)
+		}
+
+		saved, ok := conf.FindQuery(name)
+		if !ok {
+			return nil, cli.Exit(
+				fmt.Sprintf("no such saved query (%s) in %s", name, config.Path()),
+				int(exitCodeErrArgs),
+			)
+		}
+
+		str = saved.Query
+	}
+
+	if str == "" {
+		//nolint:nilnil
+		return nil, nil
+	}
+
+	query, err := filter.ParseQuery(str, time.Now())
 	if err != nil {
-		return cli.Exit(
-			fmt.Sprintf("failed to load cache: %s", err),
-			int(exitCodeErrCache),
+		return nil, cli.Exit(
+			fmt.Sprintf("invalid query: %s", err),
+			int(exitCodeErrArgs),
 		)
 	}
 
-	choises, err := ui.FindItemMulti(items)
+	return query, nil
+}
+
+//nolint:exhaustruct,exhaustivestruct
+func queryFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:    "query",
+			Aliases: []string{"Q"},
+			Usage:   `Filter expression (e.g. 'feed:golang AND unread AND published>7d AND title~"generics"')`,
+		},
+		&cli.StringFlag{
+			Name:    "folder",
+			Aliases: []string{"F"},
+			Usage:   "Name of the saved query in the config file",
+		},
+	}
+}
+
+func runOpenCommand(ctx *cli.Context) error {
+	items, err := loadItemsOrSetup()
+	if err != nil {
+		return err
+	}
+
+	selected, err := selectItems(ctx, items)
+	if err != nil {
+		return err
+	}
+
+	if ctx.Bool("print") {
+		for _, item := range selected {
+			if item.Link != "" {
+				//nolint:forbidigo
+				fmt.Println(item.Link)
+			}
+		}
+
+		return cli.Exit("", int(exitCodeOK))
+	}
+
+	conf, err := config.Load()
 	if err != nil {
 		return cli.Exit(
-			fmt.Sprintf("an error occurred on fuzzyfinder: %s", err),
-			int(exitCodeErrFuzzyFinder),
+			fmt.Sprintf("failed to load config: %s", err),
+			int(exitCodeErrConfig),
 		)
 	}
 
-	for _, idx := range choises {
-		if err := ui.OpenURL(items[idx].Link); err != nil {
+	for _, item := range selected {
+		if item.Link == "" {
+			fmt.Fprintf(os.Stderr, "No link to open: %s\n", item.Title)
+
+			continue
+		}
+
+		if err := ui.OpenURL(item.Link, conf.OpenerFor(item.FeedURL)); err != nil {
 			return cli.Exit(
-				fmt.Sprintf("failed to open URL in browser: %s", err),
+				fmt.Sprintf("failed to open URL in browser: %s "+
+					"(set opener in %s, or use --print to print the URLs instead)", err, config.Path()),
 				int(exitCodeErrBrowser),
 			)
 		}
@@ -259,103 +639,1032 @@ func runOpenCommand(ctx *cli.Context) error {
 	return cli.Exit("", int(exitCodeOK))
 }
 
-func runImportCommand(ctx *cli.Context) error {
-	if ctx.NArg() != 0 {
+func runShowCommand(ctx *cli.Context) error {
+	if ctx.NArg() == 0 {
 		return cli.Exit(
-			fmt.Sprintf("extra arguments (%s)", ctx.Args().Slice()),
+			"requires item IDs as arguments",
 			int(exitCodeErrArgs),
 		)
 	}
 
-	path := strings.TrimSpace(ctx.String("path"))
+	items, err := loadItems()
+	if err != nil {
+		return err
+	}
 
-	if path == "" {
-		return cli.Exit(
-			"requires OPML file path",
-			int(exitCodeErrArgs),
-		)
+	selected, err := selectItems(ctx, items)
+	if err != nil {
+		return err
 	}
 
-	outlines, err := opml.ParseOPML(path)
+	for i, item := range selected {
+		if i != 0 {
+			//nolint:forbidigo
+			fmt.Println()
+		}
+
+		//nolint:forbidigo
+		fmt.Print(ui.RenderItem(item))
+	}
+
+	return nil
+}
+
+func runStarCommand(ctx *cli.Context) error {
+	items, err := loadItems()
 	if err != nil {
-		return cli.Exit(
-			fmt.Sprintf("failed to parse OPML file (%s) %s", path, err),
-			int(exitCodeErrOPML),
-		)
+		return err
 	}
 
-	urls := opml.ExtractFeedURL(outlines.Outlines())
+	unstar := ctx.Bool("unstar")
+	if unstar && ctx.NArg() == 0 {
+		//nolint:exhaustruct,exhaustivestruct
+		items = filter.Apply(items, &filter.Filter{Starred: true})
+	}
 
-	for _, url := range urls {
-		if err := urlentry.Add(url); err != nil {
-			return cli.Exit(
-				fmt.Sprintf("failed to regester the URL entry (%s): %s", url, err),
-				int(exitCodeErrURLEntry),
-			)
-		}
+	selected, err := selectItems(ctx, items)
+	if err != nil {
+		return err
 	}
 
-	return cli.Exit("", int(exitCodeOK))
+	return setStarred(!unstar, selected...)
 }
 
-func runUpdateCommand(ctx *cli.Context) error {
-	if ctx.NArg() != 0 {
-		return cli.Exit(
-			fmt.Sprintf("extra arguments (%s)", ctx.Args().Slice()),
-			int(exitCodeErrArgs),
-		)
+func runMarkReadCommand(ctx *cli.Context) error {
+	items, err := loadItems()
+	if err != nil {
+		return err
 	}
 
-	urls, err := urlentry.Load()
+	selected, err := selectItems(ctx, items)
 	if err != nil {
-		return cli.Exit(
-			fmt.Sprintf("failed to load URL entry: %s", err),
-			int(exitCodeErrURLEntry),
-		)
+		return err
 	}
 
-	if len(urls) == 0 {
+	keys := make([]string, 0, len(selected))
+	for _, item := range selected {
+		keys = append(keys, item.Key())
+	}
+
+	err = withStore(func(store cache.Store) error {
+		return store.MarkRead(!ctx.Bool("unread"), keys...)
+	})
+	if err != nil {
 		return cli.Exit(
-			"URL entry not registered",
-			int(exitCodeErrURLEntry),
+			fmt.Sprintf("failed to save the cache: %s", err),
+			int(exitCodeErrCache),
 		)
 	}
 
-	//nolint:prealloc
-	var feeds []gofeed.Feed
+	return nil
+}
+
+// selectItems returns the items whose IDs are given as the arguments,
+// or lets the user select items with the fuzzyfinder if no arguments are given.
+func selectItems(ctx *cli.Context, items []*cache.Item) ([]*cache.Item, error) {
+	if ctx.NArg() != 0 {
+		selected := make([]*cache.Item, 0, ctx.NArg())
+
+		for _, id := range ctx.Args().Slice() {
+			item, err := cache.FindByID(items, id)
+			if err != nil {
+				return nil, cli.Exit(
+					fmt.Sprintf("failed to find the item: %s", err),
+					int(exitCodeErrArgs),
+				)
+			}
+
+			selected = append(selected, item)
+		}
+
+		return selected, nil
+	}
+
+	view, err := viewFromFlags(ctx)
+	if err != nil {
+		return nil, err
+	}
 
-	for _, url := range urls {
-		feed, err := fetchFeed(url)
-		if err != nil {
-			return cli.Exit(
-				fmt.Sprintf("failed to fetch the feeds: %s", err),
-				int(exitCodeErrFetchFeeds),
-			)
+	selected, err := ui.FindItemMulti(items, view)
+	if err != nil {
+		if errors.Is(fuzzyfinder.ErrAbort, err) {
+			return nil, cli.Exit("", int(exitCodeOK))
 		}
 
-		//nolint:forbidigo
-		fmt.Printf("Fetched the feed: %s\n", url)
+		return nil, cli.Exit(
+			fmt.Sprintf("an error occurred on fuzzyfinder: %s", err),
+			int(exitCodeErrFuzzyFinder),
+		)
+	}
+
+	return selected, nil
+}
 
-		feeds = append(feeds, *feed)
+// viewFromFlags returns the arrangement of the items in the finder given by the flags returned by viewFlags.
+// The default view is used for the commands without the flags.
+func viewFromFlags(ctx *cli.Context) (*ui.View, error) {
+	sortMode, groupMode := ctx.String("sort"), ctx.String("group")
+	if sortMode == "" {
+		sortMode = string(ui.SortFeed)
 	}
 
-	var items []*gofeed.Item
-	for i := 0; i < len(feeds); i++ {
-		items = append(items, feeds[i].Items...)
+	if groupMode == "" {
+		groupMode = string(ui.GroupNone)
 	}
 
-	if err := cache.Export(items); err != nil {
-		return fmt.Errorf("failed save the cache: %w", err)
+	view, err := ui.NewView(sortMode, groupMode)
+	if err != nil {
+		return nil, cli.Exit(
+			fmt.Sprintf("invalid option: %s", err),
+			int(exitCodeErrArgs),
+		)
 	}
 
-	return nil
+	return view, nil
 }
 
-func fetchFeed(url string) (*gofeed.Feed, error) {
-	feed, err := gofeed.NewParser().ParseURL(url)
-	if err != nil {
-		return nil, fmt.Errorf("failed to fetch or parse feed at %s: %w", url, err)
+//nolint:exhaustruct,exhaustivestruct
+func viewFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:    "sort",
+			Aliases: []string{"S"},
+			Usage:   fmt.Sprintf("Sort order of the items (%s)", strings.Join(ui.SortModes(), ", ")),
+			Value:   string(ui.SortFeed),
+		},
+		&cli.StringFlag{
+			Name:    "group",
+			Aliases: []string{"g"},
+			Usage:   fmt.Sprintf("Group the items (%s)", strings.Join(ui.GroupModes(), ", ")),
+			Value:   string(ui.GroupNone),
+		},
+	}
+}
+
+func runImportCommand(ctx *cli.Context) error {
+	if ctx.NArg() != 0 {
+		return cli.Exit(
+			fmt.Sprintf("extra arguments (%s)", ctx.Args().Slice()),
+			int(exitCodeErrArgs),
+		)
+	}
+
+	path := strings.TrimSpace(ctx.String("path"))
+
+	if path == "" {
+		return cli.Exit(
+			"requires OPML file path",
+			int(exitCodeErrArgs),
+		)
+	}
+
+	if err := importOPML(path); err != nil {
+		return err
+	}
+
+	return cli.Exit("", int(exitCodeOK))
+}
+
+func importOPML(path string) error {
+	outlines, err := opml.ParseOPML(path)
+	if err != nil {
+		return cli.Exit(
+			fmt.Sprintf("failed to parse OPML file (%s) %s", path, err),
+			int(exitCodeErrOPML),
+		)
+	}
+
+	urls := opml.ExtractFeedURL(outlines.Outlines())
+
+	for _, url := range urls {
+		if err := urlentry.Add(url); err != nil {
+			return cli.Exit(
+				fmt.Sprintf("failed to regester the URL entry (%s): %s", url, err),
+				int(exitCodeErrURLEntry),
+			)
+		}
+	}
+
+	return nil
+}
+
+func runUpdateCommand(ctx *cli.Context) error {
+	if ctx.NArg() != 0 {
+		return cli.Exit(
+			fmt.Sprintf("extra arguments (%s)", ctx.Args().Slice()),
+			int(exitCodeErrArgs),
+		)
+	}
+
+	urls, err := urlentry.Load()
+	if err != nil {
+		return cli.Exit(
+			fmt.Sprintf("failed to load URL entry: %s", err),
+			int(exitCodeErrURLEntry),
+		)
+	}
+
+	if len(urls) == 0 {
+		return cli.Exit(
+			"URL entry not registered",
+			int(exitCodeErrURLEntry),
+		)
+	}
+
+	if ctx.Bool("verbose") && (ctx.Bool("quiet") || ctx.Bool("json")) {
+		return cli.Exit("--verbose cannot be used with --quiet or --json", int(exitCodeErrArgs))
+	}
+
+	opts := updateOptions{
+		force:    ctx.Bool("force"),
+		deadline: ctx.String("deadline"),
+		// Only the JSON is printed to stdout.
+		quiet:   ctx.Bool("quiet") || ctx.Bool("json"),
+		verbose: ctx.Bool("verbose"),
+		listNew: ctx.Bool("list-new"),
+		json:    ctx.Bool("json"),
+	}
+
+	items, err := updateFeeds(urls, opts)
+	if items == nil {
+		return err
+	}
+
+	// The enclosures of the fetched feeds are downloaded even if some feeds failed.
+	if dlErr := autoDownload(ctx.Context, items, opts.interactive(), opts.stdout(), os.Stderr); err == nil {
+		err = dlErr
+	}
+
+	return err
+}
+
+// updateOptions are the options of the update command.
+type updateOptions struct {
+	// force fetches the feeds which are not due yet.
+	force bool
+	// deadline overrides the deadline in the config.
+	deadline string
+	// quiet prints only the errors and the changes of the feeds.
+	quiet bool
+	// verbose prints each feed when it starts and finishes instead of the progress, and the feeds which are not due.
+	verbose bool
+	// listNew lists the titles of the new items after the summary.
+	listNew bool
+	// json prints the new items of each feed as JSON lines, and the other messages to stderr.
+	json bool
+}
+
+// progress returns the writer of the progress messages, which are discarded if quiet is true.
+func (o updateOptions) progress() io.Writer {
+	if o.quiet {
+		return io.Discard
+	}
+
+	return os.Stdout
+}
+
+// stdout returns the writer of the messages which are printed to stdout unless the JSON is.
+func (o updateOptions) stdout() io.Writer {
+	if o.json {
+		return os.Stderr
+	}
+
+	return os.Stdout
+}
+
+// interactive reports whether the progress is shown on the terminal.
+func (o updateOptions) interactive() bool {
+	return !o.quiet && !o.verbose && isTerminal(os.Stdout)
+}
+
+// updateFeeds fetches the feeds which are due, saves them in the cache and returns all items in the cache.
+// The feeds which fail to fetch are reported and the others are saved; the returned items are not nil then
+// with the error.
+func updateFeeds(urls []string, opts updateOptions) ([]*cache.Item, error) {
+	conf, err := config.Load()
+	if err != nil {
+		return nil, cli.Exit(
+			fmt.Sprintf("failed to load config: %s", err),
+			int(exitCodeErrConfig),
+		)
+	}
+
+	due := urls
+
+	if !opts.force {
+		if due, err = dueFeeds(conf, urls, time.Now(), opts.verbose); err != nil {
+			return nil, err
+		}
+
+		if len(due) == 0 {
+			if !opts.quiet {
+				//nolint:forbidigo
+				fmt.Println("All feeds are up to date, use --force to fetch them anyway")
+			}
+
+			return loadItems()
+		}
+
+		if skipped := len(urls) - len(due); skipped != 0 && !opts.quiet && !opts.verbose {
+			//nolint:forbidigo
+			fmt.Printf("Skipped %d of %d feeds which are not due yet, use --force to fetch them anyway\n", skipped, len(urls))
+		}
+	}
+
+	fetchers, err := newFeedFetchers(conf)
+	if err != nil {
+		return nil, err
+	}
+
+	// Interrupting the update gives up the remaining feeds, full texts and hooks, and the fetched feeds are still saved.
+	sigctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	ctx, cancel, err := withFetchDeadline(sigctx, &conf.Fetch, opts.deadline)
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+
+	var (
+		fetched []*fetch.Result
+		errs    []error
+	)
+
+	events := make(chan ui.FeedEvent)
+
+	go func() {
+		defer close(events)
+
+		fetched, errs = fetchFeeds(ctx, fetchers, due, events)
+	}()
+
+	if opts.interactive() {
+		redacted := make([]string, len(due))
+		for i, url := range due {
+			redacted[i] = redact.URL(url)
+		}
+
+		if _, err := ui.ShowUpdate(redacted, events, cancel); err != nil {
+			// Wait for the feeds being fetched so that nothing is printed after returning.
+			cancel()
+
+			for range events {
+			}
+
+			return nil, cli.Exit(err.Error(), int(exitCodeErrFetchFeeds))
+		}
+	} else {
+		printUpdate(due, events, opts.verbose, opts.quiet)
+	}
+
+	results := make([]*fetch.Result, 0, len(due))
+
+	var gone []string
+
+	for i, url := range due {
+		switch {
+		case errs[i] == nil:
+			results = append(results, fetched[i])
+		case isGone(errs[i]):
+			gone = append(gone, url)
+		case opts.interactive():
+			// The errors are printed after the progress so that they are kept on the terminal.
+			fmt.Fprintf(os.Stderr, "Failed to fetch the feed: %s\n", errs[i])
+		}
+	}
+
+	urls, changes, err := applyFeedChanges(conf, urls, results, gone)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		items  []*cache.Item
+		added  [][]*cache.Item
+		cached map[string]*cache.Feed
+	)
+
+	// The hooks are run only for the feeds fetched before.
+	if len(conf.Hooks) != 0 {
+		if cached, err = loadFeeds(); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(results) == 0 {
+		items, err = loadItems()
+	} else {
+		items, added, err = refreshCache(sigctx, fetchers, urls, results, opts.progress(), os.Stderr)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, change := range changes {
+		fmt.Fprintln(opts.stdout(), change)
+	}
+
+	if err := printNewItems(results, added, opts); err != nil {
+		return nil, err
+	}
+
+	failures := runHooks(sigctx, conf, results, added, cached, os.Stderr)
+	for _, err := range failures {
+		fmt.Fprintf(os.Stderr, "Failed to run the hook: %s\n", err)
+	}
+
+	if failed := len(due) - len(results) - len(gone); failed != 0 {
+		return items, cli.Exit(
+			fmt.Sprintf("failed to fetch %d of %d feeds", failed, len(due)),
+			int(exitCodeErrFetchFeeds),
+		)
+	}
+
+	if len(failures) != 0 {
+		return items, cli.Exit(
+			fmt.Sprintf("failed to run %d of %d hooks", len(failures), len(conf.Hooks)),
+			int(exitCodeErrHook),
+		)
+	}
+
+	return items, nil
+}
+
+// printNewItems prints the summary of the new items of the fetched feeds, or the JSON lines of them.
+// The added items are at the indexes of the results.
+func printNewItems(results []*fetch.Result, added [][]*cache.Item, opts updateOptions) error {
+	if opts.quiet && !opts.listNew && !opts.json {
+		return nil
+	}
+
+	summaries := make([]output.FeedSummary, len(results))
+
+	for i, result := range results {
+		url := redact.URL(result.URL)
+
+		title := result.Feed.Title
+		if title == "" {
+			title = url
+		}
+
+		summaries[i] = output.FeedSummary{Title: title, URL: url, NewItems: added[i]}
+	}
+
+	var err error
+	if opts.json {
+		err = output.WriteSummaryJSON(os.Stdout, summaries)
+	} else {
+		err = output.WriteSummary(os.Stdout, summaries, opts.listNew)
+	}
+
+	if err != nil {
+		return cli.Exit(fmt.Sprintf("failed to print the new items: %s", err), int(exitCodeErrOutput))
+	}
+
+	return nil
+}
+
+// fetchConcurrency is the number of feeds fetched at a time.
+const fetchConcurrency = 4
+
+// fetchFeeds fetches the feeds with at most fetchConcurrency feeds at a time and sends their states to the events.
+// The results and the errors are at the indexes of the URLs.
+func fetchFeeds(
+	ctx context.Context, fetchers *feedFetchers, urls []string, events chan<- ui.FeedEvent,
+) ([]*fetch.Result, []error) {
+	results := make([]*fetch.Result, len(urls))
+	errs := make([]error, len(urls))
+	queue := make(chan int)
+
+	var wg sync.WaitGroup
+
+	for w := 0; w < fetchConcurrency; w++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for i := range queue {
+				events <- ui.FeedEvent{Index: i, Status: ui.FeedFetching, Elapsed: 0, Items: 0, Err: nil}
+
+				start := time.Now()
+				results[i], errs[i] = fetchFeed(ctx, fetchers, urls[i])
+				elapsed := time.Since(start)
+
+				if errs[i] != nil {
+					events <- ui.FeedEvent{Index: i, Status: ui.FeedFailed, Elapsed: elapsed, Items: 0, Err: errs[i]}
+
+					continue
+				}
+
+				events <- ui.FeedEvent{Index: i, Status: ui.FeedDone, Elapsed: elapsed, Items: len(results[i].Feed.Items), Err: nil}
+			}
+		}()
+	}
+
+	for i := range urls {
+		queue <- i
+	}
+
+	close(queue)
+	wg.Wait()
+
+	return results, errs
+}
+
+// printUpdate prints each feed when it is fetched or fails until the events are closed,
+// and also when it starts if verbose is true. Only the failures are printed if quiet is true.
+// The gone feeds are not printed since they are reported with the changes.
+func printUpdate(urls []string, events <-chan ui.FeedEvent, verbose, quiet bool) {
+	for event := range events {
+		url := redact.URL(urls[event.Index])
+
+		//nolint:forbidigo,exhaustive
+		switch event.Status {
+		case ui.FeedFetching:
+			if verbose {
+				fmt.Printf("Fetching the feed: %s\n", url)
+			}
+		case ui.FeedDone:
+			if !quiet {
+				fmt.Printf("Fetched the feed in %s (%d items): %s\n", ui.FormatElapsed(event.Elapsed), event.Items, url)
+			}
+		case ui.FeedFailed:
+			if !isGone(event.Err) {
+				fmt.Fprintf(os.Stderr, "Failed to fetch the feed in %s: %s\n", ui.FormatElapsed(event.Elapsed), event.Err)
+			}
+		}
+	}
+}
+
+// applyFeedChanges follows the permanent changes of the fetched feeds. The feeds which moved permanently are renamed
+// in the URL entry file and the cache unless they have settings in the config file, which are only reported,
+// and the gone ones are commented out in the URL entry file. It returns the URLs in the URL entry file after the changes and the messages to report them.
+// The URLs of the results are also updated.
+func applyFeedChanges(conf *config.Config, urls []string, results []*fetch.Result, gone []string) ([]string, []string, error) {
+	urls = append([]string(nil), urls...)
+
+	var changes []string
+
+	moves := make(map[string]string)
+
+	for _, result := range results {
+		if result.MovedTo == "" || result.MovedTo == result.URL {
+			continue
+		}
+
+		if contains(urls, result.MovedTo) {
+			changes = append(changes, fmt.Sprintf("The feed moved permanently to %s, which is also registered: %s",
+				redact.URL(result.MovedTo), redact.URL(result.URL)))
+
+			continue
+		}
+
+		// The settings such as the credentials would not be sent to the new URL, and the config file is not rewritten.
+		if conf.Feed(result.URL) != nil {
+			changes = append(changes, fmt.Sprintf(
+				"The feed moved permanently to %s, update its URL in the URL entry file and the config file: %s",
+				redact.URL(result.MovedTo), redact.URL(result.URL)))
+
+			continue
+		}
+
+		if err := urlentry.Replace(result.URL, result.MovedTo); err != nil {
+			return nil, nil, cli.Exit(
+				fmt.Sprintf("failed to rewrite the URL entry: %s", err),
+				int(exitCodeErrURLEntry),
+			)
+		}
+
+		changes = append(changes,
+			fmt.Sprintf("Moved the feed permanently: %s -> %s", redact.URL(result.URL), redact.URL(result.MovedTo)))
+		urls[indexOf(urls, result.URL)] = result.MovedTo
+		moves[result.URL] = result.MovedTo
+	}
+
+	if err := renameCachedFeeds(moves); err != nil {
+		return nil, nil, err
+	}
+
+	for _, result := range results {
+		if newURL, ok := moves[result.URL]; ok {
+			result.URL = newURL
+		}
+	}
+
+	note := fmt.Sprintf("410 Gone on %s", time.Now().Format("2006-01-02"))
+
+	for _, url := range gone {
+		if err := urlentry.Disable(url, note); err != nil {
+			return nil, nil, cli.Exit(
+				fmt.Sprintf("failed to disable the URL entry: %s", err),
+				int(exitCodeErrURLEntry),
+			)
+		}
+
+		changes = append(changes, fmt.Sprintf("Disabled the feed, which is gone (410): %s", redact.URL(url)))
+		urls = append(urls[:indexOf(urls, url)], urls[indexOf(urls, url)+1:]...)
+	}
+
+	return urls, changes, nil
+}
+
+// renameCachedFeeds moves the cached feeds from the old URLs to the new ones.
+//
+//nolint:nonamedreturns
+func renameCachedFeeds(moves map[string]string) (err error) {
+	if len(moves) == 0 {
+		return nil
+	}
+
+	store, err := cache.Open()
+	if err != nil {
+		return cli.Exit(fmt.Sprintf("failed to open cache: %s", err), int(exitCodeErrCache))
+	}
+
+	defer func() {
+		if e := store.Close(); e != nil && err == nil {
+			err = cli.Exit(fmt.Sprintf("failed to close cache: %s", e), int(exitCodeErrCache))
+		}
+	}()
+
+	for oldURL, newURL := range moves {
+		if err := store.RenameFeed(oldURL, newURL); err != nil {
+			return cli.Exit(err.Error(), int(exitCodeErrCache))
+		}
+	}
+
+	return nil
+}
+
+// isGone reports whether the feed is gone permanently.
+func isGone(err error) bool {
+	var statusErr *fetch.StatusError
+
+	return errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusGone
+}
+
+// dueFeeds returns the feeds which are due to be fetched at the time, and prints the ones which are not if verbose is true.
+func dueFeeds(conf *config.Config, urls []string, now time.Time, verbose bool) ([]string, error) {
+	feeds, err := loadFeeds()
+	if err != nil {
+		return nil, err
+	}
+
+	due := make([]string, 0, len(urls))
+
+	for _, url := range urls {
+		interval, err := feedInterval(conf, url, "", 0)
+		if err != nil {
+			return nil, cli.Exit(err.Error(), int(exitCodeErrConfig))
+		}
+
+		if next := nextFetch(feeds[url], interval); next.After(now) {
+			if !verbose {
+				continue
+			}
+
+			//nolint:forbidigo
+			fmt.Printf("Skipped the feed (due at %s): %s\n", next.Local().Format("15:04"), redact.URL(url))
+
+			continue
+		}
+
+		due = append(due, url)
+	}
+
+	return due, nil
+}
+
+// feedInterval returns the interval to fetch the feed, which is the one of the feed in the config,
+// the override (e.g. a flag), the global one in the config or the fallback in this order.
+func feedInterval(conf *config.Config, url, override string, fallback time.Duration) (time.Duration, error) {
+	str := override
+	if feed := conf.Feed(url); feed != nil && feed.Interval != "" {
+		str = feed.Interval
+	} else if str == "" {
+		str = conf.Interval
+	}
+
+	if str == "" {
+		return fallback, nil
+	}
+
+	interval, err := filter.ParseDuration(str)
+	if err != nil {
+		return fallback, fmt.Errorf("invalid interval of %s: %w", redact.URL(url), err)
+	}
+
+	return interval, nil
+}
+
+// nextFetch returns when the cached feed is due to be fetched next, or the zero time if it has never been fetched.
+func nextFetch(feed *cache.Feed, interval time.Duration) time.Time {
+	if feed == nil || feed.FetchedAt.IsZero() {
+		return time.Time{}
+	}
+
+	return schedule.Next(feed.FetchedAt, interval, feed.Hints)
+}
+
+// refreshCache saves the fetched feeds with their full texts in the cache, rebuilds the search index
+// and returns all items in the cache with the new items of each result. The urls are all feeds in the URL entry file, some of which may not be fetched.
+// The full texts are fetched with the fetchers of the feeds, and their number is printed to w and the failures to errw.
+func refreshCache(
+	ctx context.Context, fetchers *feedFetchers, urls []string, results []*fetch.Result, w, errw io.Writer,
+) ([]*cache.Item, [][]*cache.Item, error) {
+	fetchedURLs := make([]string, len(results))
+	newItems := make([][]*cache.Item, len(results))
+
+	for i, result := range results {
+		fetchedURLs[i] = result.URL
+		newItems[i] = cache.NewItems(result.URL, result.Feed)
+	}
+
+	if err := fetchFullTexts(ctx, fetchers, fetchedURLs, newItems, w, errw); err != nil {
+		return nil, nil, err
+	}
+
+	// The cache is opened after fetching so that other processes are not blocked while waiting for the network.
+	items, added, err := saveFeeds(urls, results, newItems)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed save the cache: %w", err)
+	}
+
+	if err := search.Build(items).Save(); err != nil {
+		return nil, nil, fmt.Errorf("failed to save the search index: %w", err)
+	}
+
+	return items, added, nil
+}
+
+// saveFeeds replaces the items of the fetched feeds in the cache and returns all items in the cache
+// with the items of each result which were not in the cache before.
+// Feeds which are no longer in the URL entry file are removed from the cache.
+//
+//nolint:nonamedreturns
+func saveFeeds(urls []string, results []*fetch.Result, newItems [][]*cache.Item) (items []*cache.Item, added [][]*cache.Item, err error) {
+	store, err := cache.Open()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open the cache: %w", err)
+	}
+
+	defer func() {
+		if e := store.Close(); e != nil && err == nil {
+			err = e
+		}
+	}()
+
+	added = make([][]*cache.Item, len(results))
+
+	for i, result := range results {
+		if added[i], err = store.ReplaceItems(result.URL, newItems[i]); err != nil {
+			return nil, nil, err
+		}
+
+		feed := cache.NewFeed(result.URL, indexOf(urls, result.URL), result.Feed)
+		feed.Hints = result.Hints
+
+		if err := store.PutFeed(feed); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	cached, err := store.Feeds()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, feed := range cached {
+		if !contains(urls, feed.URL) {
+			if err := store.DeleteFeed(feed.URL); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+
+	if items, err = store.Items(); err != nil {
+		return nil, nil, err
+	}
+
+	return items, added, nil
+}
+
+// loadItems returns all items in the cache.
+//
+//nolint:nonamedreturns
+func loadItems() (items []*cache.Item, err error) {
+	store, err := cache.OpenReadOnly()
+	if err != nil {
+		return nil, cli.Exit(
+			fmt.Sprintf("failed to open cache: %s", err),
+			int(exitCodeErrCache),
+		)
+	}
+
+	defer func() {
+		if e := store.Close(); e != nil && err == nil {
+			err = cli.Exit(fmt.Sprintf("failed to close cache: %s", e), int(exitCodeErrCache))
+		}
+	}()
+
+	items, err = store.Items()
+	if err != nil {
+		return nil, cli.Exit(
+			fmt.Sprintf("failed to load cache: %s", err),
+			int(exitCodeErrCache),
+		)
+	}
+
+	return items, nil
+}
+
+// loadFeeds returns the metadata of the feeds in the cache by the URL.
+//
+//nolint:nonamedreturns
+func loadFeeds() (feeds map[string]*cache.Feed, err error) {
+	store, err := cache.OpenReadOnly()
+	if err != nil {
+		return nil, cli.Exit(
+			fmt.Sprintf("failed to open cache: %s", err),
+			int(exitCodeErrCache),
+		)
+	}
+
+	defer func() {
+		if e := store.Close(); e != nil && err == nil {
+			err = cli.Exit(fmt.Sprintf("failed to close cache: %s", e), int(exitCodeErrCache))
+		}
+	}()
+
+	cached, err := store.Feeds()
+	if err != nil {
+		return nil, cli.Exit(
+			fmt.Sprintf("failed to load cache: %s", err),
+			int(exitCodeErrCache),
+		)
+	}
+
+	feeds = make(map[string]*cache.Feed, len(cached))
+	for _, feed := range cached {
+		feeds[feed.URL] = feed
+	}
+
+	return feeds, nil
+}
+
+// loadItemsOrSetup returns all items in the cache like loadItems.
+// On the first run, where the cache is empty, it helps the user to register feeds if there are none yet,
+// then fetches the feeds.
+func loadItemsOrSetup() ([]*cache.Item, error) {
+	items, err := loadItems()
+	if err != nil || len(items) != 0 {
+		return items, err
+	}
+
+	urls, err := urlentry.Load()
+	if err != nil {
+		return nil, cli.Exit(
+			fmt.Sprintf("failed to load URL entry: %s", err),
+			int(exitCodeErrURLEntry),
+		)
+	}
+
+	if len(urls) == 0 {
+		if urls, err = setupURLEntry(); err != nil {
+			return nil, err
+		}
+	}
+
+	fmt.Fprintln(os.Stderr, "The cache is empty, fetching the feeds...")
+
+	items, err = updateFeeds(urls, updateOptions{force: true, deadline: "", quiet: false, verbose: false, listNew: false, json: false})
+	if err != nil && len(items) == 0 {
+		return nil, err
+	}
+
+	if len(items) == 0 {
+		return nil, cli.Exit(
+			"no items found in the registered feeds",
+			int(exitCodeOK),
+		)
+	}
+
+	return items, nil
+}
+
+// setupURLEntry asks the user for a feed URL or an OPML file to import and registers it.
+func setupURLEntry() ([]string, error) {
+	input, err := ui.Prompt(
+		"Welcome to srss! No feeds are registered yet.\nEnter a feed URL to subscribe to, or the path of an OPML file to import.",
+		"https://example.com/feed.xml",
+	)
+	if err != nil || strings.TrimSpace(input) == "" {
+		return nil, cli.Exit(
+			"no feeds registered. Run `srss add <URL>` or `srss import --path <OPML file>` to register feeds",
+			int(exitCodeErrURLEntry),
+		)
+	}
+
+	input = strings.TrimSpace(input)
+
+	if _, err := os.Stat(input); err == nil {
+		err = importOPML(input)
+	} else {
+		err = urlentry.Add(input)
+	}
+
+	if err != nil {
+		return nil, cli.Exit(
+			fmt.Sprintf("failed to register the feeds: %s", err),
+			int(exitCodeErrURLEntry),
+		)
+	}
+
+	urls, err := urlentry.Load()
+	if err != nil {
+		return nil, cli.Exit(
+			fmt.Sprintf("failed to load URL entry: %s", err),
+			int(exitCodeErrURLEntry),
+		)
+	}
+
+	if len(urls) == 0 {
+		return nil, cli.Exit(
+			"URL entry not registered",
+			int(exitCodeErrURLEntry),
+		)
+	}
+
+	return urls, nil
+}
+
+// markRead marks the item as read in the cache.
+func markRead(item *cache.Item) error {
+	err := withStore(func(store cache.Store) error {
+		return store.MarkRead(true, item.Key())
+	})
+	if err != nil {
+		return cli.Exit(
+			fmt.Sprintf("failed to save the cache: %s", err),
+			int(exitCodeErrCache),
+		)
+	}
+
+	item.Read = true
+
+	return nil
+}
+
+// setStarred stars or unstars the item in the cache.
+func setStarred(starred bool, items ...*cache.Item) error {
+	err := withStore(func(store cache.Store) error {
+		for _, item := range items {
+			if starred {
+				if err := store.Star(item); err != nil {
+					return err
+				}
+
+				continue
+			}
+
+			if err := store.Unstar(item.Key()); err != nil {
+				return err
+			}
+
+			item.Starred = false
+		}
+
+		return nil
+	})
+	if err != nil {
+		return cli.Exit(
+			fmt.Sprintf("failed to save the cache: %s", err),
+			int(exitCodeErrCache),
+		)
+	}
+
+	return nil
+}
+
+func indexOf(list []string, v string) int {
+	for i, item := range list {
+		if item == v {
+			return i
+		}
+	}
+
+	return -1
+}
+
+func contains(list []string, v string) bool {
+	for _, item := range list {
+		if item == v {
+			return true
+		}
 	}
 
-	return feed, nil
+	return false
 }
@@ -4,6 +4,8 @@ import (
 	"testing"
 
 	"github.com/mmcdole/gofeed"
+	"github.com/sheepla/srss/cache"
+	"github.com/sheepla/srss/config"
 )
 
 //nolint:paralleltest
@@ -30,3 +32,31 @@ func TestFetchFeed(t *testing.T) {
 		t.Errorf("Got invalid URL but no error occurred: (feed: %s)", feed)
 	}
 }
+
+//nolint:exhaustruct,exhaustivestruct
+func TestSmartFoldersWithoutQueries(t *testing.T) {
+	t.Parallel()
+
+	items := []*cache.Item{
+		{Item: &gofeed.Item{Title: "starred", GUID: "1"}, Starred: true},
+		{Item: &gofeed.Item{Title: "plain", GUID: "2"}},
+	}
+
+	// The starred items are reachable in the default config, which has no saved queries.
+	folders, err := smartFolders(items, &config.Config{})
+	if err != nil {
+		t.Fatalf("an error occurred on `smartFolders()`: %s", err)
+	}
+
+	for _, folder := range folders {
+		if folder.Name == "Starred" {
+			if len(folder.Items) != 1 || folder.Items[0].Title != "starred" {
+				t.Errorf("unexpected items in the Starred folder: %d", len(folder.Items))
+			}
+
+			return
+		}
+	}
+
+	t.Error("no Starred folder")
+}
@@ -0,0 +1,173 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+	"time"
+
+	"github.com/sheepla/srss/cache"
+	"github.com/sheepla/srss/redact"
+)
+
+// Format is the output format of the items.
+type Format string
+
+const (
+	FormatTable Format = "table"
+	FormatJSON  Format = "json"
+	FormatTSV   Format = "tsv"
+)
+
+const dateLayout = "2006-01-02 15:04"
+
+// Formats returns the names of the supported formats.
+func Formats() []string {
+	return []string{string(FormatTable), string(FormatJSON), string(FormatTSV)}
+}
+
+// record is the representation of an item in JSON lines.
+// The credentials in the URL of the feed are masked as in the other output.
+type record struct {
+	ID          string   `json:"id"`
+	Feed        string   `json:"feed"`
+	FeedURL     string   `json:"feed_url"`
+	Title       string   `json:"title"`
+	Link        string   `json:"link"`
+	Author      string   `json:"author,omitempty"`
+	Published   string   `json:"published,omitempty"`
+	Updated     string   `json:"updated,omitempty"`
+	Categories  []string `json:"categories,omitempty"`
+	Read        bool     `json:"read"`
+	Starred     bool     `json:"starred"`
+	Description string   `json:"description,omitempty"`
+	Content     string   `json:"content,omitempty"`
+}
+
+// Write writes the items to w in the format.
+func Write(w io.Writer, items []*cache.Item, format Format) error {
+	switch format {
+	case FormatTable:
+		return writeTable(w, items)
+	case FormatJSON:
+		return writeJSON(w, items)
+	case FormatTSV:
+		return writeTSV(w, items)
+	default:
+		//nolint:goerr113
+		return fmt.Errorf("unknown output format (%s)", format)
+	}
+}
+
+// WriteTemplate executes the Go template for each item and writes the results line by line.
+func WriteTemplate(w io.Writer, items []*cache.Item, text string) error {
+	tmpl, err := template.New("item").Parse(text)
+	if err != nil {
+		return fmt.Errorf("failed to parse the template: %w", err)
+	}
+
+	for _, item := range items {
+		if err := tmpl.Execute(w, item); err != nil {
+			return fmt.Errorf("failed to execute the template: %w", err)
+		}
+
+		if !strings.HasSuffix(text, "\n") {
+			if _, err := fmt.Fprintln(w); err != nil {
+				return fmt.Errorf("failed to write: %w", err)
+			}
+		}
+	}
+
+	return nil
+}
+
+func writeTable(w io.Writer, items []*cache.Item) error {
+	//nolint:gomnd
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(tw, "ID\tFEED\tPUBLISHED\tTITLE")
+
+	for _, item := range items {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n",
+			item.ID(),
+			sanitize(item.FeedTitle),
+			formatTime(item.PublishedParsed, dateLayout),
+			sanitize(item.Title),
+		)
+	}
+
+	if err := tw.Flush(); err != nil {
+		return fmt.Errorf("failed to write: %w", err)
+	}
+
+	return nil
+}
+
+func writeJSON(w io.Writer, items []*cache.Item) error {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+
+	for _, item := range items {
+		if err := enc.Encode(newRecord(item)); err != nil {
+			return fmt.Errorf("failed to encode the item as JSON: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func writeTSV(w io.Writer, items []*cache.Item) error {
+	for _, item := range items {
+		_, err := fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n",
+			item.ID(),
+			sanitize(item.FeedTitle),
+			formatTime(item.PublishedParsed, time.RFC3339),
+			sanitize(item.Title),
+			sanitize(item.Link),
+		)
+		if err != nil {
+			return fmt.Errorf("failed to write: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func newRecord(item *cache.Item) *record {
+	author := ""
+	if item.Author != nil {
+		author = item.Author.Name
+	}
+
+	return &record{
+		ID:          item.ID(),
+		Feed:        item.FeedTitle,
+		FeedURL:     redact.URL(item.FeedURL),
+		Title:       item.Title,
+		Link:        item.Link,
+		Author:      author,
+		Published:   formatTime(item.PublishedParsed, time.RFC3339),
+		Updated:     formatTime(item.UpdatedParsed, time.RFC3339),
+		Categories:  item.Categories,
+		Read:        item.Read,
+		Starred:     item.Starred,
+		Description: item.Description,
+		Content:     item.Content,
+	}
+}
+
+func formatTime(t *time.Time, layout string) string {
+	if t == nil {
+		return ""
+	}
+
+	return t.Local().Format(layout)
+}
+
+// sanitize replaces tabs and line breaks so that a value fits in a single column.
+func sanitize(str string) string {
+	return strings.Join(strings.Fields(str), " ")
+}
@@ -0,0 +1,144 @@
+package output_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mmcdole/gofeed"
+	"github.com/sheepla/srss/cache"
+	"github.com/sheepla/srss/output"
+)
+
+//nolint:gochecknoglobals,exhaustruct,exhaustivestruct
+var (
+	published = time.Date(2022, 3, 15, 10, 30, 0, 0, time.UTC)
+	item      = &cache.Item{
+		Item: &gofeed.Item{
+			Title:           "Go 1.18\tis\nreleased",
+			Link:            "https://go.dev/blog/go1.18",
+			GUID:            "go118",
+			Author:          &gofeed.Person{Name: "Gopher"},
+			PublishedParsed: &published,
+			Categories:      []string{"release"},
+		},
+		FeedTitle: "The Go\tBlog",
+		FeedURL:   "https://go.dev/blog/feed.atom?token=secret",
+		Starred:   true,
+	}
+)
+
+func TestWrite(t *testing.T) {
+	t.Parallel()
+
+	local := published.Local()
+
+	tests := []struct {
+		format output.Format
+		want   string
+	}{
+		{
+			output.FormatTable,
+			"ID" + strings.Repeat(" ", len(item.ID())) + "FEED         PUBLISHED         TITLE\n" +
+				item.ID() + "  The Go Blog  " + local.Format("2006-01-02 15:04") + "  Go 1.18 is released\n",
+		},
+		{
+			output.FormatTSV,
+			item.ID() + "\tThe Go Blog\t" + local.Format(time.RFC3339) + "\tGo 1.18 is released\thttps://go.dev/blog/go1.18\n",
+		},
+	}
+
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		if err := output.Write(&buf, []*cache.Item{item}, tt.format); err != nil {
+			t.Fatalf("an error occurred on `Write()` in %s: %s", tt.format, err)
+		}
+
+		if buf.String() != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.format, buf.String(), tt.want)
+		}
+	}
+}
+
+func TestWriteJSON(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	if err := output.Write(&buf, []*cache.Item{item, item}, output.FormatJSON); err != nil {
+		t.Fatalf("an error occurred on `Write()`: %s", err)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines, want 2: %q", len(lines), buf.String())
+	}
+
+	var record map[string]interface{}
+	if err := json.Unmarshal([]byte(lines[0]), &record); err != nil {
+		t.Fatalf("invalid JSON line (%s): %s", lines[0], err)
+	}
+
+	want := map[string]interface{}{
+		"id":         item.ID(),
+		"feed":       "The Go\tBlog",
+		"feed_url":   "https://go.dev/blog/feed.atom?token=xxxxx",
+		"title":      "Go 1.18\tis\nreleased",
+		"link":       "https://go.dev/blog/go1.18",
+		"author":     "Gopher",
+		"published":  published.Local().Format(time.RFC3339),
+		"categories": []interface{}{"release"},
+		"read":       false,
+		"starred":    true,
+	}
+
+	if len(record) != len(want) {
+		t.Errorf("got fields %v, want %v", record, want)
+	}
+
+	for name, value := range want {
+		got, _ := json.Marshal(record[name])
+		expected, _ := json.Marshal(value)
+
+		if !bytes.Equal(got, expected) {
+			t.Errorf("field %s: got %s, want %s", name, got, expected)
+		}
+	}
+
+	if err := output.Write(&buf, nil, "xml"); err == nil {
+		t.Error("no error for the unknown format")
+	}
+}
+
+func TestWriteTemplate(t *testing.T) {
+	t.Parallel()
+
+	items := []*cache.Item{item, item}
+
+	tests := []struct {
+		text    string
+		want    string
+		wantErr bool
+	}{
+		{"{{.FeedTitle}}: {{.Link}}", "The Go\tBlog: https://go.dev/blog/go1.18\nThe Go\tBlog: https://go.dev/blog/go1.18\n", false},
+		{"{{.Author.Name}}\n", "Gopher\nGopher\n", false},
+		{"{{.Title", "", true},
+		{"{{.Missing}}", "", true},
+	}
+
+	for _, tt := range tests {
+		var buf bytes.Buffer
+
+		err := output.WriteTemplate(&buf, items, tt.text)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%q: got error %v, want error %t", tt.text, err, tt.wantErr)
+
+			continue
+		}
+
+		if buf.String() != tt.want {
+			t.Errorf("%q: got %q, want %q", tt.text, buf.String(), tt.want)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/sheepla/srss/cache"
+)
+
+// FeedSummary is a feed fetched in an update with the items which were not in the cache before.
+type FeedSummary struct {
+	Title    string
+	URL      string
+	NewItems []*cache.Item
+}
+
+// summaryRecord is the representation of a feed summary in JSON lines.
+type summaryRecord struct {
+	Feed     string    `json:"feed"`
+	FeedURL  string    `json:"feed_url"`
+	New      int       `json:"new"`
+	NewItems []*record `json:"new_items"`
+}
+
+// WriteSummary writes the number of the new items of each feed in a line, e.g. "Go Blog: 3 new, HN: 27 new",
+// followed by the titles of the new items grouped by the feeds if titles is true.
+// The feeds without new items are omitted.
+func WriteSummary(w io.Writer, feeds []FeedSummary, titles bool) error {
+	counts := make([]string, 0, len(feeds))
+
+	for _, feed := range feeds {
+		if len(feed.NewItems) != 0 {
+			counts = append(counts, fmt.Sprintf("%s: %d new", sanitize(feed.Title), len(feed.NewItems)))
+		}
+	}
+
+	if len(counts) == 0 {
+		if _, err := fmt.Fprintln(w, "No new items"); err != nil {
+			return fmt.Errorf("failed to write: %w", err)
+		}
+
+		return nil
+	}
+
+	if _, err := fmt.Fprintf(w, "New items: %s\n", strings.Join(counts, ", ")); err != nil {
+		return fmt.Errorf("failed to write: %w", err)
+	}
+
+	if !titles {
+		return nil
+	}
+
+	for _, feed := range feeds {
+		if len(feed.NewItems) == 0 {
+			continue
+		}
+
+		if _, err := fmt.Fprintf(w, "\n%s\n", sanitize(feed.Title)); err != nil {
+			return fmt.Errorf("failed to write: %w", err)
+		}
+
+		for _, item := range feed.NewItems {
+			if _, err := fmt.Fprintf(w, "  %s  %s\n", item.ID(), sanitize(item.Title)); err != nil {
+				return fmt.Errorf("failed to write: %w", err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// WriteSummaryJSON writes a JSON line for each feed with its new items, including the feeds without them.
+func WriteSummaryJSON(w io.Writer, feeds []FeedSummary) error {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+
+	for _, feed := range feeds {
+		records := make([]*record, len(feed.NewItems))
+		for i, item := range feed.NewItems {
+			records[i] = newRecord(item)
+		}
+
+		summary := &summaryRecord{Feed: feed.Title, FeedURL: feed.URL, New: len(records), NewItems: records}
+		if err := enc.Encode(summary); err != nil {
+			return fmt.Errorf("failed to encode the summary as JSON: %w", err)
+		}
+	}
+
+	return nil
+}
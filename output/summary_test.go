@@ -0,0 +1,68 @@
+package output_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/mmcdole/gofeed"
+	"github.com/sheepla/srss/cache"
+	"github.com/sheepla/srss/output"
+)
+
+//nolint:gochecknoglobals,exhaustruct,exhaustivestruct
+var summaries = []output.FeedSummary{
+	{
+		Title: "The Go Blog",
+		URL:   "https://go.dev/blog/feed.atom",
+		NewItems: []*cache.Item{
+			{Item: &gofeed.Item{Title: "Go 1.99 is released", GUID: "go199"}, FeedURL: "https://go.dev/blog/feed.atom"},
+		},
+	},
+	{Title: "Empty", URL: "https://example.com/feed.xml", NewItems: nil},
+}
+
+func TestWriteSummary(t *testing.T) {
+	t.Parallel()
+
+	item := summaries[0].NewItems[0]
+
+	tests := []struct {
+		feeds  []output.FeedSummary
+		titles bool
+		want   string
+	}{
+		{nil, false, "No new items\n"},
+		{summaries, false, "New items: The Go Blog: 1 new\n"},
+		{summaries, true, "New items: The Go Blog: 1 new\n\nThe Go Blog\n  " + item.ID() + "  Go 1.99 is released\n"},
+	}
+
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		if err := output.WriteSummary(&buf, tt.feeds, tt.titles); err != nil {
+			t.Fatalf("an error occurred on `WriteSummary()`: %s", err)
+		}
+
+		if have := buf.String(); have != tt.want {
+			t.Errorf("WriteSummary(titles=%t) = %q, want %q", tt.titles, have, tt.want)
+		}
+	}
+}
+
+func TestWriteSummaryJSON(t *testing.T) {
+	t.Parallel()
+
+	item := summaries[0].NewItems[0]
+
+	var buf bytes.Buffer
+	if err := output.WriteSummaryJSON(&buf, summaries); err != nil {
+		t.Fatalf("an error occurred on `WriteSummaryJSON()`: %s", err)
+	}
+
+	want := `{"feed":"The Go Blog","feed_url":"https://go.dev/blog/feed.atom","new":1,"new_items":[{"id":"` + item.ID() +
+		`","feed":"","feed_url":"https://go.dev/blog/feed.atom","title":"Go 1.99 is released","link":"","read":false,"starred":false}]}` + "\n" +
+		`{"feed":"Empty","feed_url":"https://example.com/feed.xml","new":0,"new_items":[]}` + "\n"
+
+	if have := buf.String(); have != want {
+		t.Errorf("WriteSummaryJSON() = %q, want %q", have, want)
+	}
+}
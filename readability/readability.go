@@ -0,0 +1,158 @@
+// Package readability extracts the main article body from a web page.
+//
+// The algorithm is a simplified version of the one of Arc90's Readability:
+// the parents of the paragraphs are scored by the length and commas of the text,
+// the class and id names and the density of the links, and the best one is taken as the article.
+package readability
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"regexp"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"golang.org/x/net/html"
+)
+
+// ErrNoContent is returned when no article body is found in the page.
+var ErrNoContent = errors.New("no article content found")
+
+const (
+	// minTextLength is the length of the text under which the article is considered not found.
+	minTextLength = 200
+	// minParagraphLength is the length of the text under which a paragraph is not scored.
+	minParagraphLength = 25
+	classWeight        = 25
+	charsPerPoint      = 100
+	maxLengthPoints    = 3
+)
+
+// nolint:gochecknoglobals
+var (
+	unlikelyTags = "script, style, noscript, iframe, form, nav, header, footer, aside, svg, button, input, select, textarea"
+
+	positivePattern = regexp.MustCompile(`(?i)article|body|content|entry|hentry|h-entry|main|page|post|text|blog|story`)
+	negativePattern = regexp.MustCompile(
+		`(?i)comment|meta|footer|footnote|sidebar|sponsor|ad-|advert|share|social|related|nav|menu|promo|widget|breadcrumb|banner|popup`,
+	)
+)
+
+// Extract returns the HTML of the main article body of the page.
+func Extract(r io.Reader) (string, error) {
+	doc, err := goquery.NewDocumentFromReader(r)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse the page: %w", err)
+	}
+
+	doc.Find(unlikelyTags).Remove()
+
+	top := topCandidate(doc)
+	if top == nil || len(strings.TrimSpace(top.Text())) < minTextLength {
+		return "", ErrNoContent
+	}
+
+	content, err := top.Html()
+	if err != nil {
+		return "", fmt.Errorf("failed to render the article: %w", err)
+	}
+
+	return strings.TrimSpace(content), nil
+}
+
+// topCandidate returns the element with the highest score.
+func topCandidate(doc *goquery.Document) *goquery.Selection {
+	scores := make(map[*html.Node]float64)
+	candidates := make([]*goquery.Selection, 0)
+
+	addScore := func(s *goquery.Selection, score float64) {
+		if s.Length() == 0 {
+			return
+		}
+
+		node := s.Get(0)
+		if _, ok := scores[node]; !ok {
+			scores[node] = classScore(s)
+			candidates = append(candidates, s)
+		}
+
+		scores[node] += score
+	}
+
+	doc.Find("p, pre, td").Each(func(_ int, p *goquery.Selection) {
+		text := strings.TrimSpace(p.Text())
+		if len(text) < minParagraphLength {
+			return
+		}
+
+		score := 1 + float64(strings.Count(text, ",")) + math.Min(float64(len(text)/charsPerPoint), maxLengthPoints)
+
+		parent := p.Parent()
+		addScore(parent, score)
+		addScore(parent.Parent(), score/2) // nolint:gomnd
+	})
+
+	var (
+		best      *goquery.Selection
+		bestScore float64
+	)
+
+	for _, candidate := range candidates {
+		score := scores[candidate.Get(0)] * (1 - linkDensity(candidate))
+		if best == nil || score > bestScore {
+			best, bestScore = candidate, score
+		}
+	}
+
+	if best == nil {
+		// Pages without paragraphs, e.g. ones using only <br>, fall back to the article or main element.
+		if s := doc.Find("article, main").First(); s.Length() != 0 {
+			return s
+		}
+	}
+
+	return best
+}
+
+// classScore scores the element by the class and id names which look like the article or not.
+func classScore(s *goquery.Selection) float64 {
+	var score float64
+
+	for _, name := range []string{s.AttrOr("class", ""), s.AttrOr("id", "")} {
+		if name == "" {
+			continue
+		}
+
+		if negativePattern.MatchString(name) {
+			score -= classWeight
+		}
+
+		if positivePattern.MatchString(name) {
+			score += classWeight
+		}
+	}
+
+	if goquery.NodeName(s) == "article" {
+		score += classWeight
+	}
+
+	return score
+}
+
+// linkDensity returns the ratio of the text in the links to all text of the element.
+func linkDensity(s *goquery.Selection) float64 {
+	length := len(s.Text())
+	if length == 0 {
+		return 0
+	}
+
+	linkLength := 0
+
+	s.Find("a").Each(func(_ int, a *goquery.Selection) {
+		linkLength += len(a.Text())
+	})
+
+	return float64(linkLength) / float64(length)
+}
@@ -0,0 +1,60 @@
+package readability
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+const page = `<!DOCTYPE html>
+<html>
+<head><title>Generics in Go</title><script>var tracking = "article content";</script></head>
+<body>
+<header><nav><a href="/">Home</a> <a href="/blog">Blog</a> <a href="/about">About the Go programming language</a></nav></header>
+<div class="layout">
+  <div class="sidebar">
+    <p><a href="/a">A very long link to another article in the sidebar</a>, <a href="/b">and another one, which is long too</a></p>
+  </div>
+  <div class="post-content">
+    <h1>An Introduction To Generics</h1>
+    <p>Go 1.18 adds support for generics, which is the biggest change we've made to Go since the first open source release.</p>
+    <p>Generics are a way of writing code that is independent of the specific types being used, so functions and types may now be written to use any of a set of types.</p>
+    <p>Generics adds three new big things to the language: type parameters for functions and types, defining interface types as sets of types, and type inference.</p>
+  </div>
+  <div id="comments">
+    <p>Great post, thanks! I have been waiting for this for years, and now it is finally here.</p>
+  </div>
+</div>
+<footer><p>Copyright, the Go Authors, all rights reserved, and some more text to make it long enough.</p></footer>
+</body>
+</html>`
+
+func TestExtract(t *testing.T) {
+	t.Parallel()
+
+	content, err := Extract(strings.NewReader(page))
+	if err != nil {
+		t.Fatalf("Extract() = %v", err)
+	}
+
+	for _, want := range []string{"An Introduction To Generics", "type parameters for functions"} {
+		if !strings.Contains(content, want) {
+			t.Errorf("the content does not contain %q:\n%s", want, content)
+		}
+	}
+
+	for _, unwanted := range []string{"Great post", "sidebar", "Copyright", "tracking", "About the Go"} {
+		if strings.Contains(content, unwanted) {
+			t.Errorf("the content contains %q:\n%s", unwanted, content)
+		}
+	}
+}
+
+func TestExtractNoContent(t *testing.T) {
+	t.Parallel()
+
+	_, err := Extract(strings.NewReader(`<html><body><p>Too short to be an article.</p></body></html>`))
+	if !errors.Is(err, ErrNoContent) {
+		t.Errorf("Extract() = %v, want ErrNoContent", err)
+	}
+}
@@ -0,0 +1,72 @@
+// Package redact hides the credentials in the URLs shown in the logs and error messages.
+package redact
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+)
+
+// Mask replaces the credentials, which is the same as the one of url.URL.Redacted.
+const Mask = "xxxxx"
+
+// sensitiveParams are the parts of the names of the query parameters which hold credentials,
+// e.g. the token of the private feeds of GitHub and GitLab.
+//
+//nolint:gochecknoglobals
+var sensitiveParams = []string{"token", "key", "secret", "password", "passwd", "auth", "session", "signature"}
+
+// URL returns the URL with the user info and the values of the sensitive query parameters masked.
+// The URL is returned as is if it cannot be parsed.
+func URL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return raw
+	}
+
+	if u.User != nil {
+		u.User = url.User(Mask)
+	}
+
+	if u.RawQuery != "" {
+		// The parameters are masked in place so that the order is kept.
+		params := strings.Split(u.RawQuery, "&")
+
+		for i, param := range params {
+			name, _, found := strings.Cut(param, "=")
+			if found && isSensitive(name) {
+				params[i] = name + "=" + Mask
+			}
+		}
+
+		u.RawQuery = strings.Join(params, "&")
+	}
+
+	return u.String()
+}
+
+// Error masks the URL of the *url.Error in the chain of the error, which the HTTP client returns, and returns the error.
+func Error(err error) error {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		urlErr.URL = URL(urlErr.URL)
+	}
+
+	return err
+}
+
+func isSensitive(name string) bool {
+	if unescaped, err := url.QueryUnescape(name); err == nil {
+		name = unescaped
+	}
+
+	name = strings.ToLower(name)
+
+	for _, sensitive := range sensitiveParams {
+		if strings.Contains(name, sensitive) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,225 @@
+// Package schedule decides when the feeds are fetched next from the hints of the feeds and servers.
+package schedule
+
+import (
+	"bytes"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mmcdole/gofeed"
+	ext "github.com/mmcdole/gofeed/extensions"
+	"github.com/mmcdole/gofeed/rss"
+)
+
+const (
+	// maxHint caps the hints so that a feed with a wrong hint is still fetched daily.
+	maxHint = 24 * time.Hour
+
+	minBackoff = time.Minute
+	maxBackoff = 6 * time.Hour
+
+	// jitterRatio spreads the fetches of the feeds so that they are not fetched at the same time.
+	jitterRatio = 0.1
+)
+
+// Hints are the minimum intervals to fetch the feed suggested by the feed and the server.
+// A zero value means no hint.
+type Hints struct {
+	// TTL is the <ttl> of the RSS feed.
+	TTL time.Duration
+	// UpdatePeriod is the sy:updatePeriod divided by the sy:updateFrequency of the syndication module.
+	UpdatePeriod time.Duration
+	// MaxAge is the max-age of the Cache-Control header of the response.
+	MaxAge time.Duration
+	// SkipHours are the hours (0-23 in GMT) in which the RSS feed should not be fetched.
+	SkipHours []int
+	// SkipDays are the days of the week (in GMT) on which the RSS feed should not be fetched.
+	SkipDays []time.Weekday
+}
+
+// ParseHints extracts the hints from the raw feed, the parsed feed and the header of the response.
+func ParseHints(raw []byte, feed *gofeed.Feed, header http.Header) Hints {
+	hints := Hints{TTL: 0, UpdatePeriod: updatePeriod(feed), MaxAge: maxAge(header), SkipHours: nil, SkipDays: nil}
+
+	if feed.FeedType == "rss" {
+		// The TTL and skip hours and days are not available in the universal feed of gofeed.
+		if parsed, err := (&rss.Parser{}).Parse(bytes.NewReader(raw)); err == nil {
+			if minutes, err := strconv.Atoi(strings.TrimSpace(parsed.TTL)); err == nil && minutes > 0 {
+				hints.TTL = time.Duration(minutes) * time.Minute
+			}
+
+			hints.SkipHours = parseSkipHours(parsed.SkipHours)
+			hints.SkipDays = parseSkipDays(parsed.SkipDays)
+		}
+	}
+
+	return hints
+}
+
+// Next returns when the feed fetched at the time is due next.
+// It is after the interval or the longer one of the hints, and out of the skip hours and days.
+func Next(fetchedAt time.Time, interval time.Duration, hints Hints) time.Time {
+	next := fetchedAt.Add(Interval(interval, hints))
+
+	// A week of hours are enough to get out of any combination of the skip hours and days.
+	for i := 0; i < 7*24 && hints.skipped(next); i++ {
+		next = next.Truncate(time.Hour).Add(time.Hour)
+	}
+
+	return next
+}
+
+func (h Hints) skipped(t time.Time) bool {
+	t = t.UTC()
+
+	for _, hour := range h.SkipHours {
+		if t.Hour() == hour {
+			return true
+		}
+	}
+
+	for _, day := range h.SkipDays {
+		if t.Weekday() == day {
+			return true
+		}
+	}
+
+	return false
+}
+
+func parseSkipHours(values []string) []int {
+	var hours []int
+
+	for _, value := range values {
+		// Some feeds use 24 for midnight.
+		if hour, err := strconv.Atoi(strings.TrimSpace(value)); err == nil && hour >= 0 && hour <= 24 {
+			hours = append(hours, hour%24) //nolint:gomnd
+		}
+	}
+
+	return hours
+}
+
+func parseSkipDays(values []string) []time.Weekday {
+	var days []time.Weekday
+
+	for _, value := range values {
+		for day := time.Sunday; day <= time.Saturday; day++ {
+			if strings.EqualFold(strings.TrimSpace(value), day.String()) {
+				days = append(days, day)
+			}
+		}
+	}
+
+	return days
+}
+
+// MinInterval returns the longest of the hints, capped to a day.
+func (h Hints) MinInterval() time.Duration {
+	interval := h.TTL
+
+	for _, d := range []time.Duration{h.UpdatePeriod, h.MaxAge} {
+		if d > interval {
+			interval = d
+		}
+	}
+
+	if interval > maxHint {
+		return maxHint
+	}
+
+	return interval
+}
+
+// Interval returns the interval to fetch the feed next, which is the configured one unless the hints ask for longer.
+func Interval(base time.Duration, hints Hints) time.Duration {
+	if hinted := hints.MinInterval(); hinted > base {
+		return hinted
+	}
+
+	return base
+}
+
+// Jitter spreads the duration randomly by ±10%.
+func Jitter(d time.Duration, rnd *rand.Rand) time.Duration {
+	//nolint:gomnd,gosec
+	return d + time.Duration((rnd.Float64()*2-1)*jitterRatio*float64(d))
+}
+
+// Backoff returns the delay to retry a feed which failed the number of times in a row,
+// which doubles from a minute up to 6 hours with jitter.
+func Backoff(failures int, rnd *rand.Rand) time.Duration {
+	delay := minBackoff
+
+	for i := 1; i < failures && delay < maxBackoff; i++ {
+		delay *= 2
+	}
+
+	if delay > maxBackoff {
+		delay = maxBackoff
+	}
+
+	return Jitter(delay, rnd)
+}
+
+// updatePeriod returns the period of the syndication module, e.g. 2 for hourly means 30 minutes.
+func updatePeriod(feed *gofeed.Feed) time.Duration {
+	sy, ok := feed.Extensions["sy"]
+	if !ok {
+		return 0
+	}
+
+	var period time.Duration
+
+	switch strings.TrimSpace(extensionValue(sy, "updatePeriod")) {
+	case "hourly":
+		period = time.Hour
+	case "daily", "":
+		// The default of the syndication module is daily.
+		period = 24 * time.Hour
+	case "weekly":
+		period = 7 * 24 * time.Hour
+	case "monthly":
+		period = 30 * 24 * time.Hour
+	case "yearly":
+		period = 365 * 24 * time.Hour
+	default:
+		return 0
+	}
+
+	if frequency, err := strconv.Atoi(strings.TrimSpace(extensionValue(sy, "updateFrequency"))); err == nil && frequency > 1 {
+		period /= time.Duration(frequency)
+	}
+
+	return period
+}
+
+func extensionValue(extensions map[string][]ext.Extension, name string) string {
+	if values := extensions[name]; len(values) != 0 {
+		return values[0].Value
+	}
+
+	return ""
+}
+
+// maxAge returns the max-age of the Cache-Control header, or zero if there is none or the response must not be cached.
+func maxAge(header http.Header) time.Duration {
+	for _, directive := range strings.Split(header.Get("Cache-Control"), ",") {
+		directive = strings.ToLower(strings.TrimSpace(directive))
+
+		if directive == "no-cache" || directive == "no-store" {
+			return 0
+		}
+
+		if value := strings.TrimPrefix(directive, "max-age="); value != directive {
+			if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+				return time.Duration(seconds) * time.Second
+			}
+		}
+	}
+
+	return 0
+}
@@ -0,0 +1,135 @@
+package schedule
+
+import (
+	"math/rand"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mmcdole/gofeed"
+)
+
+const rssWithHints = `<?xml version="1.0"?>
+<rss version="2.0" xmlns:sy="http://purl.org/rss/1.0/modules/syndication/">
+<channel>
+<title>Example</title>
+<ttl>90</ttl>
+<skipHours><hour>0</hour><hour>1</hour></skipHours>
+<skipDays><day>Sunday</day></skipDays>
+<sy:updatePeriod>hourly</sy:updatePeriod>
+<sy:updateFrequency>2</sy:updateFrequency>
+<item><title>Item</title></item>
+</channel>
+</rss>`
+
+func TestParseHints(t *testing.T) {
+	t.Parallel()
+
+	feed, err := gofeed.NewParser().Parse(strings.NewReader(rssWithHints))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	header := http.Header{}
+	header.Set("Cache-Control", "public, max-age=600")
+
+	hints := ParseHints([]byte(rssWithHints), feed, header)
+
+	want := Hints{
+		TTL:          90 * time.Minute,
+		UpdatePeriod: 30 * time.Minute,
+		MaxAge:       10 * time.Minute,
+		SkipHours:    []int{0, 1},
+		SkipDays:     []time.Weekday{time.Sunday},
+	}
+	if !reflect.DeepEqual(hints, want) {
+		t.Errorf("ParseHints() = %+v, want %+v", hints, want)
+	}
+
+	if interval := Interval(time.Hour, hints); interval != 90*time.Minute {
+		t.Errorf("Interval() = %s, want the TTL 1h30m", interval)
+	}
+
+	if interval := Interval(2*time.Hour, hints); interval != 2*time.Hour {
+		t.Errorf("Interval() = %s, want the base 2h", interval)
+	}
+}
+
+func TestMaxAge(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]time.Duration{
+		"":                       0,
+		"max-age=60":             time.Minute,
+		"no-cache, max-age=60":   0,
+		"private, Max-Age=3600":  time.Hour,
+		"max-age=invalid":        0,
+		"s-maxage=10, max-age=5": 5 * time.Second,
+	}
+
+	for value, want := range tests {
+		header := http.Header{}
+		header.Set("Cache-Control", value)
+
+		if have := maxAge(header); have != want {
+			t.Errorf("maxAge(%q) = %s, want %s", value, have, want)
+		}
+	}
+}
+
+//nolint:exhaustruct,exhaustivestruct
+func TestNext(t *testing.T) {
+	t.Parallel()
+
+	// Friday 22:30 UTC.
+	fetchedAt := time.Date(2022, 8, 12, 22, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name  string
+		hints Hints
+		want  time.Time
+	}{
+		{"no hints", Hints{}, time.Date(2022, 8, 12, 23, 0, 0, 0, time.UTC)},
+		{"ttl", Hints{TTL: 2 * time.Hour}, time.Date(2022, 8, 13, 0, 30, 0, 0, time.UTC)},
+		{"skip hours", Hints{SkipHours: []int{23, 0, 1}}, time.Date(2022, 8, 13, 2, 0, 0, 0, time.UTC)},
+		{"skip days", Hints{SkipHours: []int{23}, SkipDays: []time.Weekday{time.Saturday}}, time.Date(2022, 8, 14, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		if have := Next(fetchedAt, 30*time.Minute, tt.hints); !have.Equal(tt.want) {
+			t.Errorf("%s: Next() = %s, want %s", tt.name, have, tt.want)
+		}
+	}
+}
+
+func TestMinIntervalCapped(t *testing.T) {
+	t.Parallel()
+
+	if have := (Hints{TTL: 7 * 24 * time.Hour}).MinInterval(); have != maxHint {
+		t.Errorf("MinInterval() = %s, want %s", have, maxHint)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	t.Parallel()
+
+	rnd := rand.New(rand.NewSource(1)) //nolint:gosec
+
+	prev := time.Duration(0)
+
+	for failures := 1; failures <= 20; failures++ {
+		delay := Backoff(failures, rnd)
+
+		if delay < minBackoff*9/10 || delay > maxBackoff*11/10 {
+			t.Errorf("Backoff(%d) = %s, out of range", failures, delay)
+		}
+
+		if failures <= 5 && delay < prev {
+			t.Errorf("Backoff(%d) = %s, want larger than %s", failures, delay, prev)
+		}
+
+		prev = delay
+	}
+}
@@ -0,0 +1,256 @@
+package search
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/kirsle/configdir"
+	"github.com/sheepla/srss/atomicfile"
+	"github.com/sheepla/srss/cache"
+	"golang.org/x/net/html"
+)
+
+//nolint:gochecknoglobals
+var indexFile = filepath.Join(configdir.LocalCache(), "srss", "index.gob")
+
+// Weights of the terms by the field they appear in.
+const (
+	weightTitle    = 3.0
+	weightCategory = 2.0
+	weightAuthor   = 2.0
+	weightBody     = 1.0
+)
+
+// Parameters of the Okapi BM25 ranking function.
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+// Index is an inverted index over the title, description, content, author and categories of the cached items.
+// Documents are identified by cache.Item.Key.
+type Index struct {
+	// Postings maps a term to the weighted term frequency of each document.
+	Postings map[string]map[string]float64
+	// Lengths maps a document to its weighted number of terms.
+	Lengths map[string]float64
+}
+
+// Result is an item matched by the search with its relevance score.
+type Result struct {
+	Item  *cache.Item
+	Score float64
+}
+
+// Build creates the index of the items.
+func Build(items []*cache.Item) *Index {
+	index := &Index{
+		Postings: make(map[string]map[string]float64),
+		Lengths:  make(map[string]float64, len(items)),
+	}
+
+	for _, item := range items {
+		index.add(item)
+	}
+
+	return index
+}
+
+func (index *Index) add(item *cache.Item) {
+	key := item.Key()
+
+	addText := func(text string, weight float64) {
+		for _, term := range Tokenize(text) {
+			postings, ok := index.Postings[term]
+			if !ok {
+				postings = make(map[string]float64)
+				index.Postings[term] = postings
+			}
+
+			postings[key] += weight
+			index.Lengths[key] += weight
+		}
+	}
+
+	addText(item.Title, weightTitle)
+	addText(strings.Join(item.Categories, " "), weightCategory)
+
+	if item.Author != nil {
+		addText(item.Author.Name, weightAuthor)
+	}
+
+	addText(stripHTML(item.Description), weightBody)
+	addText(stripHTML(item.Content), weightBody)
+	addText(stripHTML(item.FullText), weightBody)
+}
+
+// Search returns the items containing all terms of the text, ordered by relevance.
+func (index *Index) Search(items []*cache.Item, text string) []Result {
+	terms := Tokenize(text)
+	if len(terms) == 0 || len(index.Lengths) == 0 {
+		return nil
+	}
+
+	var total float64
+	for _, length := range index.Lengths {
+		total += length
+	}
+
+	avgLength := total / float64(len(index.Lengths))
+	count := float64(len(index.Lengths))
+
+	results := make([]Result, 0)
+
+	for _, item := range items {
+		key := item.Key()
+
+		score, ok := 0.0, true
+
+		for _, term := range terms {
+			postings := index.Postings[term]
+
+			freq, found := postings[key]
+			if !found {
+				ok = false
+
+				break
+			}
+
+			idf := math.Log(1 + (count-float64(len(postings))+0.5)/(float64(len(postings))+0.5))
+			norm := 1 - bm25B + bm25B*index.Lengths[key]/avgLength
+			score += idf * freq * (bm25K1 + 1) / (freq + bm25K1*norm)
+		}
+
+		if ok {
+			results = append(results, Result{Item: item, Score: score})
+		}
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Score > results[j].Score
+	})
+
+	return results
+}
+
+// Save writes the index to the index file in the cache directory.
+func (index *Index) Save() error {
+	if err := os.MkdirAll(filepath.Dir(indexFile), os.ModePerm); err != nil {
+		return fmt.Errorf("failed to create the directory (%s): %w", filepath.Dir(indexFile), err)
+	}
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(index); err != nil {
+		return fmt.Errorf("failed to encode the search index: %w", err)
+	}
+
+	//nolint:gomnd
+	if err := atomicfile.WriteFile(indexFile, buf.Bytes(), 0o666); err != nil {
+		return fmt.Errorf("failed to write the search index (%s): %w", indexFile, err)
+	}
+
+	return nil
+}
+
+// Path returns the path of the index file.
+func Path() string {
+	return indexFile
+}
+
+// Load reads the index file in the cache directory.
+// The error wraps os.ErrNotExist if the index has not been built yet.
+func Load() (*Index, error) {
+	file, err := os.Open(indexFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open the search index (%s): %w", indexFile, err)
+	}
+	defer file.Close()
+
+	//nolint:exhaustruct,exhaustivestruct
+	index := &Index{}
+	if err := gob.NewDecoder(file).Decode(index); err != nil {
+		return nil, fmt.Errorf("failed to decode the search index (%s): %w", indexFile, err)
+	}
+
+	return index, nil
+}
+
+// Tokenize splits the text into lower-cased terms.
+// Runs of CJK characters, which are not separated by spaces, are split into bigrams.
+func Tokenize(text string) []string {
+	var (
+		terms []string
+		word  []rune
+		cjk   []rune
+	)
+
+	flushWord := func() {
+		if len(word) > 0 {
+			terms = append(terms, string(word))
+			word = word[:0]
+		}
+	}
+
+	flushCJK := func() {
+		switch {
+		case len(cjk) == 1:
+			terms = append(terms, string(cjk))
+		case len(cjk) > 1:
+			for i := 0; i+1 < len(cjk); i++ {
+				terms = append(terms, string(cjk[i:i+2]))
+			}
+		}
+
+		cjk = cjk[:0]
+	}
+
+	for _, r := range text {
+		switch {
+		case isCJK(r):
+			flushWord()
+
+			cjk = append(cjk, r)
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			flushCJK()
+
+			word = append(word, unicode.ToLower(r))
+		default:
+			flushWord()
+			flushCJK()
+		}
+	}
+
+	flushWord()
+	flushCJK()
+
+	return terms
+}
+
+func isCJK(r rune) bool {
+	// The prolonged sound mark belongs to the Common script but is part of Katakana words.
+	return r == 'ー' || unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul)
+}
+
+func stripHTML(content string) string {
+	var buf strings.Builder
+
+	tokenizer := html.NewTokenizer(strings.NewReader(content))
+
+	for {
+		switch tokenizer.Next() {
+		case html.ErrorToken:
+			return buf.String()
+		case html.TextToken:
+			buf.Write(tokenizer.Text())
+			buf.WriteByte(' ')
+		case html.StartTagToken, html.EndTagToken, html.SelfClosingTagToken, html.CommentToken, html.DoctypeToken:
+		}
+	}
+}
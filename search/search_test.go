@@ -0,0 +1,56 @@
+package search_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/mmcdole/gofeed"
+	"github.com/sheepla/srss/cache"
+	"github.com/sheepla/srss/search"
+)
+
+func TestTokenize(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string][]string{
+		"Hello, World!": {"hello", "world"},
+		"Go 1.18 リリース":  {"go", "1", "18", "リリ", "リー", "ース"},
+		"  ":            nil,
+	}
+
+	for text, want := range tests {
+		if have := search.Tokenize(text); !reflect.DeepEqual(have, want) {
+			t.Errorf("Tokenize(%q) = %q, want %q", text, have, want)
+		}
+	}
+}
+
+//nolint:exhaustruct,exhaustivestruct
+func TestSearch(t *testing.T) {
+	t.Parallel()
+
+	items := []*cache.Item{
+		{Item: &gofeed.Item{GUID: "1", Title: "Generics in Go", Content: "<p>type parameters</p>"}},
+		{Item: &gofeed.Item{GUID: "2", Title: "Go 1.18 is released", Description: "It includes generics"}},
+		{Item: &gofeed.Item{GUID: "3", Title: "Rust 1.0", Description: "Announcing Rust"}},
+	}
+
+	index := search.Build(items)
+
+	results := index.Search(items, "generics")
+	if len(results) != 2 {
+		t.Fatalf("got %d results, want 2", len(results))
+	}
+
+	if results[0].Item != items[0] {
+		t.Errorf("the item with the term in the title should be ranked first, got %q", results[0].Item.Title)
+	}
+
+	if results := index.Search(items, "type parameters"); len(results) != 1 {
+		t.Errorf("got %d results for the terms in HTML content, want 1", len(results))
+	}
+
+	if results := index.Search(items, "generics rust"); len(results) != 0 {
+		t.Errorf("got %d results for the terms in different items, want 0", len(results))
+	}
+}
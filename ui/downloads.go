@@ -0,0 +1,182 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/progress"
+	tea "github.com/charmbracelet/bubbletea"
+	lip "github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+	"github.com/sheepla/srss/download"
+)
+
+const (
+	progressWidth   = 30
+	downloadsMargin = 4
+)
+
+type (
+	downloadEventMsg download.Event
+	downloadsDoneMsg struct{}
+)
+
+type downloadsModel struct {
+	jobs     []*download.Job
+	states   []download.Event
+	events   <-chan download.Event
+	cancel   func()
+	canceled bool
+	progress progress.Model
+	width    int
+	height   int
+}
+
+// ShowDownloads shows the queue of the downloads with the progress received from the events until the channel is closed.
+// Pressing q or Ctrl-C calls cancel and waits for the downloads to stop.
+// The last states of the jobs are returned.
+func ShowDownloads(jobs []*download.Job, events <-chan download.Event, cancel func()) ([]download.Event, error) {
+	states := make([]download.Event, len(jobs))
+	for i := range states {
+		states[i] = download.Event{Index: i, Status: download.StatusPending, Done: 0, Total: -1, Err: nil}
+	}
+
+	m := &downloadsModel{
+		jobs:     jobs,
+		states:   states,
+		events:   events,
+		cancel:   cancel,
+		canceled: false,
+		progress: progress.New(progress.WithDefaultGradient(), progress.WithWidth(progressWidth)),
+		width:    0,
+		height:   0,
+	}
+
+	if err := tea.NewProgram(m).Start(); err != nil {
+		return nil, fmt.Errorf("an error occurred on the download queue: %w", err)
+	}
+
+	return m.states, nil
+}
+
+func (m *downloadsModel) Init() tea.Cmd {
+	return m.waitEvent
+}
+
+func (m *downloadsModel) waitEvent() tea.Msg {
+	event, ok := <-m.events
+	if !ok {
+		return downloadsDoneMsg{}
+	}
+
+	return downloadEventMsg(event)
+}
+
+// nolint:ireturn
+func (m *downloadsModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		if k := msg.String(); (k == "ctrl+c" || k == "q" || k == "esc") && !m.canceled {
+			m.canceled = true
+			m.cancel()
+		}
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+	case downloadEventMsg:
+		m.states[msg.Index] = download.Event(msg)
+
+		return m, m.waitEvent
+	case downloadsDoneMsg:
+		return m, tea.Quit
+	}
+
+	return m, nil
+}
+
+func (m *downloadsModel) View() string {
+	var buf strings.Builder
+
+	counts := make(map[download.Status]int)
+	for _, state := range m.states {
+		counts[state.Status]++
+	}
+
+	fmt.Fprintf(&buf, "Downloads: %d done, %d skipped, %d failed, %d/%d remaining",
+		counts[download.StatusDone], counts[download.StatusSkipped], counts[download.StatusFailed],
+		counts[download.StatusPending]+counts[download.StatusActive], len(m.jobs))
+
+	if m.canceled {
+		buf.WriteString(" (canceling...)")
+	}
+
+	buf.WriteString("\n\n")
+
+	order := m.rowOrder()
+
+	rows := len(order)
+	if m.height > downloadsMargin && rows > m.height-downloadsMargin {
+		rows = m.height - downloadsMargin
+	}
+
+	for _, i := range order[:rows] {
+		buf.WriteString(m.renderRow(i))
+		buf.WriteString("\n")
+	}
+
+	if rows < len(order) {
+		fmt.Fprintf(&buf, "  ... and %d more\n", len(order)-rows)
+	}
+
+	buf.WriteString("\n(q to cancel)\n")
+
+	return buf.String()
+}
+
+// rowOrder returns the indexes of the jobs, the active ones first followed by the pending, failed and finished ones.
+func (m *downloadsModel) rowOrder() []int {
+	rank := map[download.Status]int{
+		download.StatusActive:  0,
+		download.StatusPending: 1,
+		download.StatusFailed:  2,
+		download.StatusDone:    3,
+		download.StatusSkipped: 4,
+	}
+
+	order := make([]int, len(m.states))
+	for i := range order {
+		order[i] = i
+	}
+
+	sort.SliceStable(order, func(a, b int) bool {
+		return rank[m.states[order[a]].Status] < rank[m.states[order[b]].Status]
+	})
+
+	return order
+}
+
+func (m *downloadsModel) renderRow(i int) string {
+	state, job := m.states[i], m.jobs[i]
+
+	var status string
+
+	switch state.Status {
+	case download.StatusActive:
+		if state.Total > 0 {
+			status = m.progress.ViewAs(float64(state.Done) / float64(state.Total))
+		} else {
+			status = fmt.Sprintf("%-*s", progressWidth, FormatBytes(state.Done))
+		}
+	case download.StatusFailed:
+		status = lip.NewStyle().Foreground(lip.Color("1")).Render(fmt.Sprintf("%-*s", progressWidth, "failed"))
+	case download.StatusPending, download.StatusDone, download.StatusSkipped:
+		status = fmt.Sprintf("%-*s", progressWidth, state.Status)
+	}
+
+	title := job.Item.Title
+	if width := m.width - progressWidth - downloadsMargin; width > 0 {
+		title = runewidth.Truncate(title, width, "…")
+	}
+
+	return fmt.Sprintf("  %s  %s", status, title)
+}
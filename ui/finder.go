@@ -2,44 +2,241 @@ package ui
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/ktr0731/go-fuzzyfinder"
 	"github.com/mattn/go-runewidth"
-	"github.com/mmcdole/gofeed"
+	"github.com/sheepla/srss/cache"
 )
 
 const padding = 5
 
-// nolint:wrapcheck
-func FindItem(items []*gofeed.Item) (int, error) {
-	return fuzzyfinder.Find(
-		items,
-		func(i int) string {
-			return fmt.Sprintf("%s [%s]", items[i].Title, humanizeTime(items[i].PublishedParsed))
-		},
-		fuzzyfinder.WithPreviewWindow(func(i, width, height int) string {
-			if i == -1 {
-				return ""
+// FindItem lets the user select one of the items arranged by the view.
+// If the view is not nil, the entries to change the sort and group modes follow the items,
+// and selecting one of them shows the finder again with the new arrangement.
+// Otherwise the items are shown as they are, e.g. in the order of relevance.
+func FindItem(items []*cache.Item, view *View) (*cache.Item, error) {
+	for {
+		list := newItemList(items, view)
+
+		idx, err := fuzzyfinder.Find(
+			list.entries(),
+			list.label,
+			fuzzyfinder.WithPreviewWindow(list.preview),
+		)
+		if err != nil {
+			// nolint:wrapcheck
+			return nil, err
+		}
+
+		if idx < len(list.items) {
+			return list.items[idx], nil
+		}
+
+		list.toggle(idx)
+	}
+}
+
+// FindItemMulti is like FindItem but lets the user select multiple items.
+// The entries to change the view are only applied if no item is selected with them,
+// so that the selected items are not dropped.
+func FindItemMulti(items []*cache.Item, view *View) ([]*cache.Item, error) {
+	for {
+		list := newItemList(items, view)
+
+		choises, err := fuzzyfinder.FindMulti(
+			list.entries(),
+			list.label,
+			fuzzyfinder.WithPreviewWindow(list.preview),
+		)
+		if err != nil {
+			// nolint:wrapcheck
+			return nil, err
+		}
+
+		selected := make([]*cache.Item, 0, len(choises))
+		toggles := make([]int, 0, numViewEntries)
+
+		for _, idx := range choises {
+			if idx < len(list.items) {
+				selected = append(selected, list.items[idx])
+			} else {
+				toggles = append(toggles, idx)
 			}
+		}
 
-			return runewidth.Wrap(renderPreviewWindow(items[i]), width/2-padding)
-		}),
+		if len(selected) != 0 || len(toggles) == 0 {
+			return selected, nil
+		}
+
+		for _, idx := range toggles {
+			list.toggle(idx)
+		}
+	}
+}
+
+// itemList is the entries of the finder: the arranged items followed by the entries to change the view.
+type itemList struct {
+	items []*cache.Item
+	view  *View
+}
+
+const (
+	entrySort = iota
+	entryGroup
+	numViewEntries
+)
+
+func newItemList(items []*cache.Item, view *View) *itemList {
+	if view == nil {
+		return &itemList{items: items, view: nil}
+	}
+
+	return &itemList{items: view.Arrange(items), view: view}
+}
+
+// entries returns the slice passed to the fuzzyfinder, only its length matters.
+func (l *itemList) entries() []struct{} {
+	if l.view == nil {
+		return make([]struct{}, len(l.items))
+	}
+
+	return make([]struct{}, len(l.items)+numViewEntries)
+}
+
+func (l *itemList) label(i int) string {
+	if i < len(l.items) {
+		if l.view == nil {
+			return renderItemLabel(l.items[i])
+		}
+
+		return l.view.label(l.items[i])
+	}
+
+	switch i - len(l.items) {
+	case entrySort:
+		return fmt.Sprintf("⇅ Sort: %s", l.view.Sort)
+	case entryGroup:
+		return fmt.Sprintf("▤ Group: %s", l.view.Group)
+	}
+
+	return ""
+}
+
+func (l *itemList) preview(i, width, height int) string {
+	if i == -1 {
+		return ""
+	}
+
+	if i < len(l.items) {
+		return runewidth.Wrap(renderPreviewWindow(l.items[i]), width/2-padding)
+	}
+
+	var (
+		title   string
+		modes   []string
+		current string
 	)
+
+	switch i - len(l.items) {
+	case entrySort:
+		title, modes, current = "Sort order", SortModes(), string(l.view.Sort)
+	case entryGroup:
+		title, modes, current = "Grouping", GroupModes(), string(l.view.Group)
+	}
+
+	var buf strings.Builder
+
+	fmt.Fprintf(&buf, "■ %s\n\n  Select this entry to switch to the next mode\n\n", title)
+
+	for _, mode := range modes {
+		if mode == current {
+			fmt.Fprintf(&buf, "  ▸ %s\n", mode)
+		} else {
+			fmt.Fprintf(&buf, "    %s\n", mode)
+		}
+	}
+
+	return runewidth.Wrap(buf.String(), width/2-padding)
+}
+
+func (l *itemList) toggle(i int) {
+	switch i - len(l.items) {
+	case entrySort:
+		l.view.nextSort()
+	case entryGroup:
+		l.view.nextGroup()
+	}
+}
+
+// Folder is a virtual folder which lists the items matched by a saved query.
+// If Search is true, the folder is an entry to search the articles instead.
+type Folder struct {
+	Name   string
+	Query  string
+	Items  []*cache.Item
+	Search bool
 }
 
 // nolint:wrapcheck
-func FindItemMulti(items []*gofeed.Item) ([]int, error) {
-	return fuzzyfinder.FindMulti(
-		items,
+func FindFolder(folders []Folder) (int, error) {
+	return fuzzyfinder.Find(
+		folders,
 		func(i int) string {
-			return fmt.Sprintf("%s [%s]", items[i].Title, humanizeTime(items[i].PublishedParsed))
+			if folders[i].Search {
+				return folders[i].Name
+			}
+
+			return fmt.Sprintf("%s (%d/%d)", folders[i].Name, countUnread(folders[i].Items), len(folders[i].Items))
 		},
 		fuzzyfinder.WithPreviewWindow(func(i, width, height int) string {
 			if i == -1 {
 				return ""
 			}
 
-			return runewidth.Wrap(renderPreviewWindow(items[i]), width/2-padding)
+			return runewidth.Wrap(renderFolderPreview(folders[i]), width/2-padding)
 		}),
 	)
 }
+
+func renderFolderPreview(folder Folder) string {
+	var buf strings.Builder
+
+	fmt.Fprintf(&buf, "■ %s\n\n", folder.Name)
+
+	if folder.Search {
+		buf.WriteString("  Search the title, description, content, author and categories of the articles\n")
+
+		return buf.String()
+	}
+
+	if folder.Query != "" {
+		fmt.Fprintf(&buf, "  %s\n\n", folder.Query)
+	}
+
+	for _, item := range folder.Items {
+		fmt.Fprintf(&buf, "- %s\n", item.Title)
+	}
+
+	return buf.String()
+}
+
+func countUnread(items []*cache.Item) int {
+	count := 0
+
+	for _, item := range items {
+		if !item.Read {
+			count++
+		}
+	}
+
+	return count
+}
+
+func renderItemLabel(item *cache.Item) string {
+	if item.Starred {
+		return fmt.Sprintf("★ %s [%s]", item.Title, humanizeTime(item.PublishedParsed))
+	}
+
+	return fmt.Sprintf("%s [%s]", item.Title, humanizeTime(item.PublishedParsed))
+}
@@ -0,0 +1,174 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/mmcdole/gofeed"
+)
+
+const defaultMediaPlayer = "mpv"
+
+// nolint:gochecknoglobals
+var mediaPlayer = defaultMediaPlayer
+
+var (
+	// ErrNoEnclosure is returned when the item has no enclosure to play.
+	ErrNoEnclosure = errors.New("no enclosure")
+	// ErrEmptyCommand is returned when the media player or opener command is empty.
+	ErrEmptyCommand = errors.New("command is empty")
+)
+
+// SetMediaPlayer changes the command to play the enclosures.
+// The URL replaces {url} in the command, or is appended to it if there is no {url}.
+func SetMediaPlayer(command string) {
+	if command == "" {
+		command = defaultMediaPlayer
+	}
+
+	mediaPlayer = command
+}
+
+// PlayMedia runs the media player with the URL in the foreground and waits until it exits.
+func PlayMedia(url string) error {
+	return runCommand(mediaPlayer, url)
+}
+
+// enclosureURL returns the URL of the n-th (1-origin) enclosure of the item.
+func enclosureURL(item *gofeed.Item, n int) (string, error) {
+	if n < 1 || n > len(item.Enclosures) || item.Enclosures[n-1] == nil || item.Enclosures[n-1].URL == "" {
+		return "", fmt.Errorf("%w: %d", ErrNoEnclosure, n)
+	}
+
+	return item.Enclosures[n-1].URL, nil
+}
+
+// renderMedia lists the image and enclosures of the item with the type, size and duration.
+func renderMedia(item *gofeed.Item) string {
+	var buf strings.Builder
+
+	if item.ITunesExt != nil {
+		if ep := item.ITunesExt.Episode; ep != "" {
+			if season := item.ITunesExt.Season; season != "" {
+				fmt.Fprintf(&buf, "  season %s, episode %s\n", season, ep)
+			} else {
+				fmt.Fprintf(&buf, "  episode %s\n", ep)
+			}
+		}
+
+		if item.ITunesExt.Subtitle != "" {
+			fmt.Fprintf(&buf, "  %s\n", item.ITunesExt.Subtitle)
+		}
+	}
+
+	if image := itemImage(item); image != "" {
+		fmt.Fprintf(&buf, "  image: %s\n", image)
+	}
+
+	duration := ""
+	if item.ITunesExt != nil {
+		duration = formatDuration(item.ITunesExt.Duration)
+	}
+
+	for i, enclosure := range item.Enclosures {
+		if enclosure == nil {
+			continue
+		}
+
+		info := make([]string, 0, 3) // nolint:gomnd
+		if enclosure.Type != "" {
+			info = append(info, enclosure.Type)
+		}
+
+		if size := formatSize(enclosure.Length); size != "" {
+			info = append(info, size)
+		}
+
+		// The duration of iTunes extension is of the episode, i.e. the first enclosure.
+		if i == 0 && duration != "" {
+			info = append(info, duration)
+		}
+
+		fmt.Fprintf(&buf, "  [%d] %s", i+1, enclosure.URL)
+
+		if len(info) != 0 {
+			fmt.Fprintf(&buf, " (%s)", strings.Join(info, ", "))
+		}
+
+		buf.WriteString("\n")
+	}
+
+	return buf.String()
+}
+
+func itemImage(item *gofeed.Item) string {
+	if item.Image != nil && item.Image.URL != "" {
+		return item.Image.URL
+	}
+
+	if item.ITunesExt != nil {
+		return item.ITunesExt.Image
+	}
+
+	return ""
+}
+
+// formatSize formats the length of the enclosure in bytes.
+// Zero and invalid lengths, which are common in the feeds, are ignored.
+func formatSize(length string) string {
+	size, err := strconv.ParseInt(strings.TrimSpace(length), 10, 64)
+	if err != nil || size <= 0 {
+		return ""
+	}
+
+	return FormatBytes(size)
+}
+
+// FormatBytes formats the size in bytes with the binary units, e.g. 12.1 KiB.
+func FormatBytes(size int64) string {
+	const unit = 1024
+
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+
+	return fmt.Sprintf("%.1f %ciB", float64(size)/float64(div), "KMGTPE"[exp])
+}
+
+// formatDuration normalizes the itunes:duration, which is either seconds, MM:SS or HH:MM:SS, to [H:]MM:SS.
+// nolint:gomnd
+func formatDuration(duration string) string {
+	parts := strings.Split(strings.TrimSpace(duration), ":")
+	if len(parts) > 3 {
+		return ""
+	}
+
+	seconds := 0
+
+	for _, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return ""
+		}
+
+		seconds = seconds*60 + n
+	}
+
+	if seconds == 0 {
+		return ""
+	}
+
+	if h := seconds / 3600; h > 0 {
+		return fmt.Sprintf("%d:%02d:%02d", h, seconds%3600/60, seconds%60)
+	}
+
+	return fmt.Sprintf("%02d:%02d", seconds/60, seconds%60)
+}
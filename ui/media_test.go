@@ -0,0 +1,90 @@
+package ui
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/mmcdole/gofeed"
+	ext "github.com/mmcdole/gofeed/extensions"
+)
+
+func TestFormatDuration(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]string{
+		"":         "",
+		"0":        "",
+		"90":       "01:30",
+		"45:12":    "45:12",
+		"1:02:03":  "1:02:03",
+		"3723":     "1:02:03",
+		"abc":      "",
+		"1:2:3:4":  "",
+		" 05:00 ":  "05:00",
+		"00:59:59": "59:59",
+	}
+
+	for in, want := range tests {
+		if have := formatDuration(in); have != want {
+			t.Errorf("formatDuration(%q) = %q, want %q", in, have, want)
+		}
+	}
+}
+
+func TestFormatSize(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]string{
+		"":         "",
+		"0":        "",
+		"-1":       "",
+		"512":      "512 B",
+		"12345":    "12.1 KiB",
+		"52428800": "50.0 MiB",
+	}
+
+	for in, want := range tests {
+		if have := formatSize(in); have != want {
+			t.Errorf("formatSize(%q) = %q, want %q", in, have, want)
+		}
+	}
+}
+
+//nolint:exhaustruct,exhaustivestruct
+func TestRenderMedia(t *testing.T) {
+	t.Parallel()
+
+	item := &gofeed.Item{
+		Enclosures: []*gofeed.Enclosure{
+			{URL: "https://example.com/ep1.mp3", Length: "52428800", Type: "audio/mpeg"},
+			{URL: "https://example.com/ep1.pdf", Type: "application/pdf"},
+		},
+		ITunesExt: &ext.ITunesItemExtension{Duration: "3723", Episode: "1", Image: "https://example.com/ep1.jpg"},
+	}
+
+	have := renderMedia(item)
+
+	for _, want := range []string{
+		"episode 1",
+		"image: https://example.com/ep1.jpg",
+		"[1] https://example.com/ep1.mp3 (audio/mpeg, 50.0 MiB, 1:02:03)",
+		"[2] https://example.com/ep1.pdf (application/pdf)",
+	} {
+		if !strings.Contains(have, want) {
+			t.Errorf("renderMedia() = %q, want to contain %q", have, want)
+		}
+	}
+
+	if url, err := enclosureURL(item, 2); err != nil || url != "https://example.com/ep1.pdf" {
+		t.Errorf("enclosureURL(2) = %q, %v", url, err)
+	}
+
+	if _, err := enclosureURL(item, 3); !errors.Is(err, ErrNoEnclosure) {
+		t.Errorf("enclosureURL(3) error = %v, want ErrNoEnclosure", err)
+	}
+
+	if have := renderMedia(&gofeed.Item{}); have != "" {
+		t.Errorf("renderMedia() of an item without media = %q, want empty", have)
+	}
+}
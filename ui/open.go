@@ -2,14 +2,71 @@ package ui
 
 import (
 	"fmt"
+	"os"
+	"os/exec"
+	"strings"
 
 	"github.com/toqueteos/webbrowser"
 )
 
-func OpenURL(url string) error {
-	if err := webbrowser.Open(url); err != nil {
+// OpenURL opens the URL with the opener command, or the default browser of the system if the opener is empty.
+// The URL replaces {url} in the opener, or is appended to it if there is no {url}.
+func OpenURL(url, opener string) error {
+	if strings.TrimSpace(opener) == "" {
+		if err := webbrowser.Open(url); err != nil {
+			return fmt.Errorf("failed to open the URL (%s): %w", url, err)
+		}
+
+		return nil
+	}
+
+	if err := runCommand(opener, url); err != nil {
 		return fmt.Errorf("failed to open the URL (%s): %w", url, err)
 	}
 
 	return nil
 }
+
+// runCommand runs the command template with the URL in the foreground so that terminal programs such as w3m work.
+func runCommand(template, url string) error {
+	args := commandArgs(template, url)
+	if len(args) == 0 {
+		return ErrEmptyCommand
+	}
+
+	// nolint:gosec
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to run %s: %w", args[0], err)
+	}
+
+	return nil
+}
+
+// commandArgs splits the command template into the arguments and replaces {url} in them with the URL.
+// The URL is appended if there is no {url}.
+func commandArgs(template, url string) []string {
+	args := strings.Fields(template)
+	if len(args) == 0 {
+		return nil
+	}
+
+	replaced := false
+
+	for i := range args {
+		if strings.Contains(args[i], "{url}") {
+			args[i] = strings.ReplaceAll(args[i], "{url}", url)
+			replaced = true
+		}
+	}
+
+	if !replaced {
+		args = append(args, url)
+	}
+
+	return args
+}
@@ -0,0 +1,28 @@
+package ui
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCommandArgs(t *testing.T) {
+	t.Parallel()
+
+	url := "https://example.com/a?b=c&d=e"
+
+	tests := []struct {
+		template string
+		want     []string
+	}{
+		{"w3m", []string{"w3m", url}},
+		{"firefox --new-tab {url}", []string{"firefox", "--new-tab", url}},
+		{"open-in --url={url} --title x", []string{"open-in", "--url=" + url, "--title", "x"}},
+		{"  ", nil},
+	}
+
+	for _, tt := range tests {
+		if have := commandArgs(tt.template, url); !reflect.DeepEqual(have, tt.want) {
+			t.Errorf("commandArgs(%q) = %q, want %q", tt.template, have, tt.want)
+		}
+	}
+}
@@ -7,7 +7,7 @@ import (
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	lip "github.com/charmbracelet/lipgloss"
-	"github.com/mmcdole/gofeed"
+	"github.com/sheepla/srss/cache"
 )
 
 const useHighPerformanceRenderer = true
@@ -30,25 +30,73 @@ var (
 )
 
 type model struct {
+	item     *cache.Item
 	title    string
 	content  string
+	starred  bool
+	play     string
+	status   string
 	ready    bool
 	viewport viewport.Model
 }
 
+// Pager shows the content of an item.
+type Pager struct {
+	program *tea.Program
+	model   *model
+}
+
 // nolint:exhaustivestruct,exhaustruct
-func NewPager(item *gofeed.Item) (*tea.Program, error) {
+func NewPager(item *cache.Item) (*Pager, error) {
+	m := &model{
+		item:    item,
+		ready:   false,
+		title:   item.Title,
+		content: renderContent(item),
+		starred: item.Starred,
+	}
+
 	program := tea.NewProgram(
-		&model{
-			ready:   false,
-			title:   item.Title,
-			content: renderContent(item),
-		},
+		m,
 		tea.WithAltScreen(),
 		tea.WithMouseCellMotion(),
 	)
 
-	return program, nil
+	return &Pager{program: program, model: m}, nil
+}
+
+// Start runs the pager until the user quits.
+func (p *Pager) Start() error {
+	if err := p.program.Start(); err != nil {
+		return fmt.Errorf("failed to start the pager: %w", err)
+	}
+
+	return nil
+}
+
+// Starred reports whether the user left the item starred, which can be toggled with the s key.
+func (p *Pager) Starred() bool {
+	return p.model.starred
+}
+
+// Media returns the URL of the enclosure selected to play, which quits the pager.
+// It is empty if the user quit the pager without selecting one.
+func (p *Pager) Media() string {
+	return p.model.play
+}
+
+// enclosureKey returns the number of the enclosure to play by the key:
+// p plays the first one and 1-9 play the n-th one.
+func enclosureKey(key string) (int, bool) {
+	if key == "p" {
+		return 1, true
+	}
+
+	if len(key) == 1 && key[0] >= '1' && key[0] <= '9' {
+		return int(key[0] - '0'), true
+	}
+
+	return 0, false
 }
 
 func (m *model) Init() tea.Cmd {
@@ -64,9 +112,20 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		m.status = ""
 		if k := msg.String(); k == "ctrl+c" || k == "q" || k == "esc" {
 			return m, tea.Quit
 		}
+		if n, ok := enclosureKey(msg.String()); ok {
+			url, err := enclosureURL(m.item.Item, n)
+			if err != nil {
+				m.status = err.Error()
+			} else {
+				m.play = url
+
+				return m, tea.Quit
+			}
+		}
 		if msg.String() == "g" {
 			m.viewport.GotoTop()
 			cmds = append(cmds, viewport.Sync(m.viewport))
@@ -75,6 +134,9 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.viewport.GotoBottom()
 			cmds = append(cmds, viewport.Sync(m.viewport))
 		}
+		if msg.String() == "s" {
+			m.starred = !m.starred
+		}
 	case tea.WindowSizeMsg:
 		headerHeight := lip.Height(m.renderHeader())
 		footerHeight := lip.Height(m.renderFooter())
@@ -112,16 +174,41 @@ func (m *model) View() string {
 
 func (m *model) renderHeader() string {
 	title := titleStyle.Render(m.title)
+	if m.starred {
+		title = titleStyle.Render("★ " + m.title)
+	}
+
 	line := strings.Repeat("─", larger(0, m.viewport.Width-lip.Width(title)))
 
 	return lip.JoinHorizontal(lip.Center, title, line)
 }
 
 func (m *model) renderFooter() string {
+	hints := titleStyle.Render(m.keyHints())
 	info := infoStyle.Render(scrollPercent(m.viewport.ScrollPercent()))
-	line := strings.Repeat("─", larger(0, m.viewport.Width-lip.Width(info)))
+	if m.status != "" {
+		info = infoStyle.Render(m.status + " " + scrollPercent(m.viewport.ScrollPercent()))
+	}
+	line := strings.Repeat("─", larger(0, m.viewport.Width-lip.Width(hints)-lip.Width(info)))
+
+	return lip.JoinHorizontal(lip.Center, hints, line, info)
+}
+
+// keyHints returns the keys to star the item and play its enclosures, which are not shown elsewhere.
+func (m *model) keyHints() string {
+	hints := []string{"s star"}
+	if m.starred {
+		hints[0] = "s unstar"
+	}
+
+	// nolint:gomnd
+	if n := len(m.item.Enclosures); n > 1 {
+		hints = append(hints, fmt.Sprintf("p/1-%d play", smaller(n, 9)))
+	} else if n == 1 {
+		hints = append(hints, "p play")
+	}
 
-	return lip.JoinHorizontal(lip.Center, line, info)
+	return strings.Join(append(hints, "q quit"), " · ")
 }
 
 // nolint:gomnd
@@ -129,6 +216,14 @@ func scrollPercent(p float64) string {
 	return fmt.Sprintf("%3.f%%", p*100)
 }
 
+func smaller(a, b int) int {
+	if a < b {
+		return a
+	}
+
+	return b
+}
+
 func larger(a, b int) int {
 	if a > b {
 		return a
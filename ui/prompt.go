@@ -0,0 +1,69 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// ErrPromptCanceled is returned by Prompt when the user cancels the input.
+var ErrPromptCanceled = errors.New("prompt canceled")
+
+type promptModel struct {
+	label    string
+	input    textinput.Model
+	canceled bool
+}
+
+// Prompt asks the user to input a line of text.
+func Prompt(label, placeholder string) (string, error) {
+	input := textinput.New()
+	input.Placeholder = placeholder
+	input.Focus()
+
+	model, err := tea.NewProgram(&promptModel{
+		label:    label,
+		input:    input,
+		canceled: false,
+	}).StartReturningModel()
+	if err != nil {
+		return "", fmt.Errorf("an error occurred on prompt: %w", err)
+	}
+
+	m, ok := model.(*promptModel)
+	if !ok || m.canceled {
+		return "", ErrPromptCanceled
+	}
+
+	return m.input.Value(), nil
+}
+
+func (m *promptModel) Init() tea.Cmd {
+	return textinput.Blink
+}
+
+// nolint:ireturn
+func (m *promptModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch msg.Type {
+		case tea.KeyEnter:
+			return m, tea.Quit
+		case tea.KeyCtrlC, tea.KeyEsc:
+			m.canceled = true
+
+			return m, tea.Quit
+		default:
+		}
+	}
+
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+
+	return m, cmd
+}
+
+func (m *promptModel) View() string {
+	return fmt.Sprintf("%s\n\n%s\n\n(Enter to submit, Esc to cancel)\n", m.label, m.input.View())
+}
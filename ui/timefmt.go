@@ -0,0 +1,140 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// TimeFormat is how the dates of the items are displayed.
+type TimeFormat struct {
+	// Absolute displays the dates with Layout instead of the relative time such as "3h ago".
+	Absolute bool
+	Layout   string
+	Location *time.Location
+	// Locale is the language of the relative time, "en" or "ja".
+	Locale string
+}
+
+const defaultTimeLayout = "2006-01-02 15:04"
+
+// nolint:gochecknoglobals
+var timeFormat = &TimeFormat{
+	Absolute: false,
+	Layout:   defaultTimeLayout,
+	Location: time.Local,
+	Locale:   DefaultLocale(),
+}
+
+// SetTimeFormat changes how the dates are displayed in the finder and pager.
+func SetTimeFormat(format *TimeFormat) {
+	timeFormat = format
+
+	if timeFormat.Layout == "" {
+		timeFormat.Layout = defaultTimeLayout
+	}
+
+	if timeFormat.Location == nil {
+		timeFormat.Location = time.Local
+	}
+}
+
+// DefaultLocale returns the locale of the relative time from the LC_ALL, LC_MESSAGES and LANG environment variables.
+func DefaultLocale() string {
+	for _, name := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if value := os.Getenv(name); value != "" {
+			if strings.HasPrefix(value, "ja") {
+				return "ja"
+			}
+
+			return "en"
+		}
+	}
+
+	return "en"
+}
+
+type relativeUnits struct {
+	now                                  string
+	minute, hour, day, week, month, year string
+	ago, later                           string
+}
+
+// nolint:gochecknoglobals
+var locales = map[string]relativeUnits{
+	"en": {
+		now:    "just now",
+		minute: "m", hour: "h", day: "d", week: "w", month: "mo", year: "y",
+		ago: "%s ago", later: "in %s",
+	},
+	"ja": {
+		now:    "たった今",
+		minute: "分", hour: "時間", day: "日", week: "週間", month: "か月", year: "年",
+		ago: "%s前", later: "%s後",
+	},
+}
+
+// Locales returns the supported locales of the relative time.
+func Locales() []string {
+	return []string{"en", "ja"}
+}
+
+//nolint:varnamelen
+func humanizeTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+
+	if timeFormat.Absolute {
+		return t.In(timeFormat.Location).Format(timeFormat.Layout)
+	}
+
+	return relativeTime(*t, time.Now(), timeFormat.Locale)
+}
+
+// relativeTime returns the time relative to now such as "3h ago" or "in 2d".
+//
+//nolint:gomnd,cyclop
+func relativeTime(t, now time.Time, locale string) string {
+	units, ok := locales[locale]
+	if !ok {
+		units = locales["en"]
+	}
+
+	diff := now.Sub(t)
+	format := units.ago
+
+	if diff < 0 {
+		diff = -diff
+		format = units.later
+	}
+
+	const (
+		day   = 24 * time.Hour
+		week  = 7 * day
+		month = 30 * day
+		year  = 365 * day
+	)
+
+	var amount string
+
+	switch {
+	case diff < time.Minute:
+		return units.now
+	case diff < time.Hour:
+		amount = fmt.Sprintf("%d%s", diff/time.Minute, units.minute)
+	case diff < day:
+		amount = fmt.Sprintf("%d%s", diff/time.Hour, units.hour)
+	case diff < week:
+		amount = fmt.Sprintf("%d%s", diff/day, units.day)
+	case diff < month:
+		amount = fmt.Sprintf("%d%s", diff/week, units.week)
+	case diff < year:
+		amount = fmt.Sprintf("%d%s", diff/month, units.month)
+	default:
+		amount = fmt.Sprintf("%d%s", diff/year, units.year)
+	}
+
+	return fmt.Sprintf(format, amount)
+}
@@ -0,0 +1,249 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/progress"
+	"github.com/charmbracelet/bubbles/spinner"
+	tea "github.com/charmbracelet/bubbletea"
+	lip "github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+)
+
+// statusWidth is the width of the status column of the feeds, e.g. "failed 12.3s".
+const statusWidth = 14
+
+// FeedStatus is the state of a feed in an update.
+type FeedStatus int
+
+const (
+	FeedPending FeedStatus = iota
+	FeedFetching
+	FeedDone
+	FeedFailed
+)
+
+func (s FeedStatus) String() string {
+	switch s {
+	case FeedPending:
+		return "pending"
+	case FeedFetching:
+		return "fetching"
+	case FeedDone:
+		return "done"
+	case FeedFailed:
+		return "failed"
+	}
+
+	return "unknown"
+}
+
+// FeedEvent is a change of the state of the feed at the index.
+// Elapsed is the time spent to fetch the feed, and Items is the number of the items of the fetched feed.
+type FeedEvent struct {
+	Index   int
+	Status  FeedStatus
+	Elapsed time.Duration
+	Items   int
+	Err     error
+}
+
+type (
+	feedEventMsg  FeedEvent
+	updateDoneMsg struct{}
+)
+
+type updateModel struct {
+	urls     []string
+	states   []FeedEvent
+	started  []time.Time
+	events   <-chan FeedEvent
+	cancel   func()
+	canceled bool
+	spinner  spinner.Model
+	progress progress.Model
+	width    int
+	height   int
+}
+
+// ShowUpdate shows the feeds being fetched with the progress received from the events until the channel is closed.
+// The URLs are shown as given, so they should be redacted by the caller.
+// Pressing q or Ctrl-C calls cancel and waits for the update to stop.
+// The last states of the feeds are returned.
+func ShowUpdate(urls []string, events <-chan FeedEvent, cancel func()) ([]FeedEvent, error) {
+	states := make([]FeedEvent, len(urls))
+	for i := range states {
+		states[i] = FeedEvent{Index: i, Status: FeedPending, Elapsed: 0, Items: 0, Err: nil}
+	}
+
+	spin := spinner.New()
+	spin.Spinner = spinner.Dot
+
+	m := &updateModel{
+		urls:     urls,
+		states:   states,
+		started:  make([]time.Time, len(urls)),
+		events:   events,
+		cancel:   cancel,
+		canceled: false,
+		spinner:  spin,
+		progress: progress.New(progress.WithDefaultGradient(), progress.WithWidth(progressWidth)),
+		width:    0,
+		height:   0,
+	}
+
+	if err := tea.NewProgram(m).Start(); err != nil {
+		return nil, fmt.Errorf("an error occurred on the update progress: %w", err)
+	}
+
+	return m.states, nil
+}
+
+func (m *updateModel) Init() tea.Cmd {
+	return tea.Batch(m.waitEvent, m.spinner.Tick)
+}
+
+func (m *updateModel) waitEvent() tea.Msg {
+	event, ok := <-m.events
+	if !ok {
+		return updateDoneMsg{}
+	}
+
+	return feedEventMsg(event)
+}
+
+// nolint:ireturn
+func (m *updateModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		if k := msg.String(); (k == "ctrl+c" || k == "q" || k == "esc") && !m.canceled {
+			m.canceled = true
+			m.cancel()
+		}
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+	case spinner.TickMsg:
+		var cmd tea.Cmd
+		m.spinner, cmd = m.spinner.Update(msg)
+
+		return m, cmd
+	case feedEventMsg:
+		if msg.Status == FeedFetching {
+			m.started[msg.Index] = time.Now()
+		}
+
+		m.states[msg.Index] = FeedEvent(msg)
+
+		return m, m.waitEvent
+	case updateDoneMsg:
+		return m, tea.Quit
+	}
+
+	return m, nil
+}
+
+func (m *updateModel) View() string {
+	var buf strings.Builder
+
+	counts := make(map[FeedStatus]int)
+	for _, state := range m.states {
+		counts[state.Status]++
+	}
+
+	finished := counts[FeedDone] + counts[FeedFailed]
+
+	fmt.Fprintf(&buf, "Feeds: %d fetched, %d failed, %d/%d remaining",
+		counts[FeedDone], counts[FeedFailed], len(m.urls)-finished, len(m.urls))
+
+	if m.canceled {
+		buf.WriteString(" (canceling...)")
+	}
+
+	buf.WriteString("\n\n")
+
+	ratio := 1.0
+	if len(m.urls) != 0 {
+		ratio = float64(finished) / float64(len(m.urls))
+	}
+
+	buf.WriteString("  " + m.progress.ViewAs(ratio) + "\n\n")
+
+	order := m.rowOrder()
+
+	rows := len(order)
+	if margin := downloadsMargin + 2; m.height > margin && rows > m.height-margin {
+		rows = m.height - margin
+	}
+
+	for _, i := range order[:rows] {
+		buf.WriteString(m.renderRow(i))
+		buf.WriteString("\n")
+	}
+
+	if rows < len(order) {
+		fmt.Fprintf(&buf, "  ... and %d more\n", len(order)-rows)
+	}
+
+	buf.WriteString("\n(q to cancel)\n")
+
+	return buf.String()
+}
+
+// rowOrder returns the indexes of the feeds, the fetching ones first followed by the pending, failed and fetched ones.
+func (m *updateModel) rowOrder() []int {
+	rank := map[FeedStatus]int{
+		FeedFetching: 0,
+		FeedPending:  1,
+		FeedFailed:   2,
+		FeedDone:     3,
+	}
+
+	order := make([]int, len(m.states))
+	for i := range order {
+		order[i] = i
+	}
+
+	sort.SliceStable(order, func(a, b int) bool {
+		return rank[m.states[order[a]].Status] < rank[m.states[order[b]].Status]
+	})
+
+	return order
+}
+
+func (m *updateModel) renderRow(i int) string {
+	state := m.states[i]
+
+	var status string
+
+	switch state.Status {
+	case FeedFetching:
+		status = fmt.Sprintf("%s %-*s", m.spinner.View(), statusWidth-2, FormatElapsed(time.Since(m.started[i])))
+	case FeedDone:
+		status = lip.NewStyle().Foreground(lip.Color("2")).
+			Render(fmt.Sprintf("%-*s", statusWidth, "✓ "+FormatElapsed(state.Elapsed)))
+	case FeedFailed:
+		status = lip.NewStyle().Foreground(lip.Color("1")).
+			Render(fmt.Sprintf("%-*s", statusWidth, "✗ "+FormatElapsed(state.Elapsed)))
+	case FeedPending:
+		status = fmt.Sprintf("%-*s", statusWidth, state.Status)
+	}
+
+	url := m.urls[i]
+	if width := m.width - statusWidth - downloadsMargin; width > 0 {
+		url = runewidth.Truncate(url, width, "…")
+	}
+
+	return fmt.Sprintf("  %s  %s", status, url)
+}
+
+// FormatElapsed formats the time spent on a task, e.g. 850ms or 12.3s.
+func FormatElapsed(d time.Duration) string {
+	if d < time.Second {
+		return d.Round(time.Millisecond).String()
+	}
+
+	return d.Round(100 * time.Millisecond).String() //nolint:gomnd
+}
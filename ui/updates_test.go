@@ -0,0 +1,68 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/bubbles/progress"
+	"github.com/charmbracelet/bubbles/spinner"
+)
+
+func TestFormatElapsed(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		elapsed time.Duration
+		want    string
+	}{
+		{850*time.Millisecond + 400*time.Microsecond, "850ms"},
+		{12*time.Second + 340*time.Millisecond, "12.3s"},
+		{90 * time.Second, "1m30s"},
+	}
+
+	for _, tt := range tests {
+		if have := FormatElapsed(tt.elapsed); have != tt.want {
+			t.Errorf("FormatElapsed(%s) = %q, want %q", tt.elapsed, have, tt.want)
+		}
+	}
+}
+
+func TestUpdateModelView(t *testing.T) {
+	t.Parallel()
+
+	urls := []string{"https://example.com/done.xml", "https://example.com/failed.xml", "https://example.com/pending.xml"}
+	events := make(chan FeedEvent)
+
+	m := &updateModel{
+		urls: urls,
+		states: []FeedEvent{
+			{Index: 0, Status: FeedDone, Elapsed: time.Second, Items: 3, Err: nil},
+			{Index: 1, Status: FeedFailed, Elapsed: 2 * time.Second, Items: 0, Err: nil},
+			{Index: 2, Status: FeedPending, Elapsed: 0, Items: 0, Err: nil},
+		},
+		started:  make([]time.Time, len(urls)),
+		events:   events,
+		cancel:   func() {},
+		canceled: false,
+		spinner:  spinner.New(),
+		progress: progress.New(),
+		width:    0,
+		height:   0,
+	}
+
+	view := m.View()
+
+	if !strings.HasPrefix(view, "Feeds: 1 fetched, 1 failed, 1/3 remaining\n") {
+		t.Errorf("unexpected header: %q", view)
+	}
+
+	// The pending feed comes first, followed by the failed and fetched ones.
+	pending := strings.Index(view, "pending.xml")
+	failed := strings.Index(view, "failed.xml")
+	done := strings.Index(view, "done.xml")
+
+	if pending < 0 || !(pending < failed && failed < done) {
+		t.Errorf("unexpected order of the feeds: %q", view)
+	}
+}
@@ -4,13 +4,12 @@ import (
 	"bytes"
 	"fmt"
 	"strings"
-	"time"
 
-	"github.com/mmcdole/gofeed"
+	"github.com/sheepla/srss/cache"
 	"golang.org/x/net/html"
 )
 
-func renderPreviewWindow(item *gofeed.Item) string {
+func renderPreviewWindow(item *cache.Item) string {
 	author := func() string {
 		if item.Author != nil {
 			return item.Author.Name
@@ -42,16 +41,25 @@ func renderPreviewWindow(item *gofeed.Item) string {
 	}()
 
 	return fmt.Sprintf(
-		"■ %s\n\n  %s\n\n  %s %s\n\n%s\n",
+		"■ %s\n\n  %s\n  %s\n\n  %s %s\n\n%s%s\n",
 		item.Title,
+		sprintfIfNotEmpty("id: %s", item.ID()),
 		sprintfIfNotEmpty("by %s", author),
 		sprintfIfNotEmpty("published at %s", publishedAt),
 		sprintfIfNotEmpty("updated at %s", updatedAt),
+		sprintfIfNotEmpty("%s\n", renderMedia(item.Item)),
 		description,
 	)
 }
 
-func renderContent(item *gofeed.Item) string {
+// RenderItem renders the title, ID and content of the item as plain text.
+func RenderItem(item *cache.Item) string {
+	return fmt.Sprintf("■ %s\nid: %s\nfeed: %s\n\n%s", item.Title, item.ID(), item.FeedTitle, renderContent(item))
+}
+
+// renderContent renders the item as plain text.
+// The full text of the article is shown instead of the content of the feed if it has been fetched.
+func renderContent(item *cache.Item) string {
 	author := func() string {
 		if item.Author != nil {
 			return item.Author.Name
@@ -74,9 +82,14 @@ func renderContent(item *gofeed.Item) string {
 		return item.Updated
 	}()
 	content := func() string {
-		c, err := renderHTML(item.Content)
+		html := item.Content
+		if item.FullText != "" {
+			html = item.FullText
+		}
+
+		c, err := renderHTML(html)
 		if err != nil {
-			return item.Content
+			return html
 		}
 
 		return c
@@ -93,7 +106,7 @@ func renderContent(item *gofeed.Item) string {
 	return fmt.Sprintf(
 		`%s%s %s
 ──────
-%s
+%s%s
 %s
 ──────
 %s
@@ -101,6 +114,7 @@ func renderContent(item *gofeed.Item) string {
 		author,
 		sprintfIfNotEmpty("published at %s", publishedAt),
 		sprintfIfNotEmpty("updated at %s", updatedAt),
+		sprintfIfNotEmpty("%s──────\n", renderMedia(item.Item)),
 		sprintfIfNotEmpty("%s", description),
 		sprintfIfNotEmpty("%s", content),
 		sprintfIfNotEmpty("%s", strings.Join(item.Links, "\n")),
@@ -115,31 +129,6 @@ func sprintfIfNotEmpty(format string, str string) string {
 	return fmt.Sprintf(format, str)
 }
 
-//nolint:gomnd,varnamelen
-func humanizeTime(t *time.Time) string {
-	if t == nil {
-		return ""
-	}
-
-	now := time.Now()
-	diff := int(now.Sub(*t).Hours())
-	day := diff / 24
-
-	if day >= 30 {
-		month := day / 30
-
-		return fmt.Sprintf("%dmon ago", month)
-	}
-
-	if day == 0 {
-		hours := diff % 24
-
-		return fmt.Sprintf("%dh ago", hours)
-	}
-
-	return fmt.Sprintf("%dd ago", day)
-}
-
 func renderHTML(content string) (string, error) {
 	doc, err := html.Parse(strings.NewReader(content))
 	if err != nil {
@@ -162,4 +151,18 @@ func removeHTMLTags(node *html.Node, buf *bytes.Buffer) {
 	for child := node.FirstChild; child != nil; child = child.NextSibling {
 		removeHTMLTags(child, buf)
 	}
+
+	// Block elements are separated by line breaks, which the extracted articles often lack.
+	if node.Type == html.ElementNode && isBlockElement(node.Data) && buf.Len() != 0 && !bytes.HasSuffix(buf.Bytes(), []byte("\n")) {
+		buf.WriteString("\n")
+	}
+}
+
+func isBlockElement(tag string) bool {
+	switch tag {
+	case "p", "br", "div", "li", "pre", "blockquote", "h1", "h2", "h3", "h4", "h5", "h6", "tr":
+		return true
+	}
+
+	return false
 }
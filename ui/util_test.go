@@ -2,6 +2,7 @@ package ui
 
 import (
 	"testing"
+	"time"
 )
 
 func TestHumanizeTime(t *testing.T) {
@@ -11,3 +12,35 @@ func TestHumanizeTime(t *testing.T) {
 		t.Error("e!")
 	}
 }
+
+func TestRelativeTime(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2022, 8, 15, 12, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+
+	tests := []struct {
+		diff   time.Duration
+		locale string
+		want   string
+	}{
+		{10 * time.Second, "en", "just now"},
+		{5 * time.Minute, "en", "5m ago"},
+		{3 * time.Hour, "en", "3h ago"},
+		{2 * day, "en", "2d ago"},
+		{15 * day, "en", "2w ago"},
+		{90 * day, "en", "3mo ago"},
+		{800 * day, "en", "2y ago"},
+		{-2 * time.Hour, "en", "in 2h"},
+		{5 * time.Minute, "ja", "5分前"},
+		{15 * day, "ja", "2週間前"},
+		{-3 * day, "ja", "3日後"},
+		{3 * time.Hour, "unknown", "3h ago"},
+	}
+
+	for _, test := range tests {
+		if have := relativeTime(now.Add(-test.diff), now, test.locale); have != test.want {
+			t.Errorf("relativeTime(%s, %s) = %q, want %q", test.diff, test.locale, have, test.want)
+		}
+	}
+}
@@ -0,0 +1,218 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/sheepla/srss/cache"
+)
+
+// SortMode is the order of the items in the finder.
+type SortMode string
+
+const (
+	SortFeed   SortMode = "feed"
+	SortNewest SortMode = "newest"
+	SortOldest SortMode = "oldest"
+	SortTitle  SortMode = "title"
+	SortUnread SortMode = "unread"
+)
+
+// GroupMode is how the items are grouped in the finder.
+type GroupMode string
+
+const (
+	GroupNone GroupMode = "none"
+	GroupFeed GroupMode = "feed"
+	GroupDay  GroupMode = "day"
+)
+
+// nolint:gochecknoglobals
+var (
+	sortModes  = []SortMode{SortFeed, SortNewest, SortOldest, SortTitle, SortUnread}
+	groupModes = []GroupMode{GroupNone, GroupFeed, GroupDay}
+)
+
+// SortModes returns the names of the sort modes.
+func SortModes() []string {
+	names := make([]string, 0, len(sortModes))
+	for _, mode := range sortModes {
+		names = append(names, string(mode))
+	}
+
+	return names
+}
+
+// GroupModes returns the names of the group modes.
+func GroupModes() []string {
+	names := make([]string, 0, len(groupModes))
+	for _, mode := range groupModes {
+		names = append(names, string(mode))
+	}
+
+	return names
+}
+
+// View is the arrangement of the items in the finder.
+type View struct {
+	Sort  SortMode
+	Group GroupMode
+}
+
+// NewView validates the names of the sort and group modes.
+func NewView(sortMode, groupMode string) (*View, error) {
+	view := &View{Sort: SortMode(sortMode), Group: GroupMode(groupMode)}
+
+	if !containsMode(SortModes(), sortMode) {
+		//nolint:goerr113
+		return nil, fmt.Errorf("unknown sort mode (%s), must be one of %s", sortMode, strings.Join(SortModes(), ", "))
+	}
+
+	if !containsMode(GroupModes(), groupMode) {
+		//nolint:goerr113
+		return nil, fmt.Errorf("unknown group mode (%s), must be one of %s", groupMode, strings.Join(GroupModes(), ", "))
+	}
+
+	return view, nil
+}
+
+func containsMode(modes []string, mode string) bool {
+	for _, m := range modes {
+		if m == mode {
+			return true
+		}
+	}
+
+	return false
+}
+
+// nextSort switches to the next sort mode.
+func (v *View) nextSort() {
+	for i, mode := range sortModes {
+		if mode == v.Sort {
+			v.Sort = sortModes[(i+1)%len(sortModes)]
+
+			return
+		}
+	}
+
+	v.Sort = sortModes[0]
+}
+
+// nextGroup switches to the next group mode.
+func (v *View) nextGroup() {
+	for i, mode := range groupModes {
+		if mode == v.Group {
+			v.Group = groupModes[(i+1)%len(groupModes)]
+
+			return
+		}
+	}
+
+	v.Group = groupModes[0]
+}
+
+// Arrange returns a copy of the items sorted and grouped by the view.
+// The items in the same feed keep the order of the feed if the sort mode does not decide the order.
+//
+//nolint:cyclop
+func (v *View) Arrange(items []*cache.Item) []*cache.Item {
+	arranged := make([]*cache.Item, len(items))
+	copy(arranged, items)
+
+	// Items without the date come last in both orders.
+	newer := func(a, b *cache.Item) bool {
+		ta, tb := a.PublishedParsed, b.PublishedParsed
+		if ta == nil || tb == nil {
+			return ta != nil
+		}
+
+		return ta.After(*tb)
+	}
+	older := func(a, b *cache.Item) bool {
+		ta, tb := a.PublishedParsed, b.PublishedParsed
+		if ta == nil || tb == nil {
+			return ta != nil
+		}
+
+		return ta.Before(*tb)
+	}
+
+	switch v.Sort {
+	case SortNewest:
+		sort.SliceStable(arranged, func(i, j int) bool { return newer(arranged[i], arranged[j]) })
+	case SortOldest:
+		sort.SliceStable(arranged, func(i, j int) bool { return older(arranged[i], arranged[j]) })
+	case SortTitle:
+		sort.SliceStable(arranged, func(i, j int) bool {
+			return strings.ToLower(arranged[i].Title) < strings.ToLower(arranged[j].Title)
+		})
+	case SortUnread:
+		sort.SliceStable(arranged, func(i, j int) bool {
+			if arranged[i].Read != arranged[j].Read {
+				return !arranged[i].Read
+			}
+
+			return newer(arranged[i], arranged[j])
+		})
+	case SortFeed:
+	}
+
+	switch v.Group {
+	case GroupFeed:
+		order := make(map[string]int)
+
+		for _, item := range items {
+			if _, ok := order[item.FeedURL]; !ok {
+				order[item.FeedURL] = len(order)
+			}
+		}
+
+		sort.SliceStable(arranged, func(i, j int) bool {
+			return order[arranged[i].FeedURL] < order[arranged[j].FeedURL]
+		})
+	case GroupDay:
+		sort.SliceStable(arranged, func(i, j int) bool {
+			di, dj := dayOf(arranged[i]), dayOf(arranged[j])
+			if di == "" || dj == "" {
+				return di != ""
+			}
+
+			if v.Sort == SortOldest {
+				return di < dj
+			}
+
+			return di > dj
+		})
+	case GroupNone:
+	}
+
+	return arranged
+}
+
+// label returns the line of the item in the finder with the name of its group.
+func (v *View) label(item *cache.Item) string {
+	switch v.Group {
+	case GroupFeed:
+		return fmt.Sprintf("%s │ %s", item.FeedTitle, renderItemLabel(item))
+	case GroupDay:
+		day := dayOf(item)
+		if day == "" {
+			day = "----------"
+		}
+
+		return fmt.Sprintf("%s │ %s", day, renderItemLabel(item))
+	case GroupNone:
+	}
+
+	return renderItemLabel(item)
+}
+
+func dayOf(item *cache.Item) string {
+	if item.PublishedParsed == nil {
+		return ""
+	}
+
+	return item.PublishedParsed.Local().Format("2006-01-02")
+}
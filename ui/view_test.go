@@ -0,0 +1,53 @@
+package ui
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mmcdole/gofeed"
+	"github.com/sheepla/srss/cache"
+)
+
+//nolint:exhaustruct,exhaustivestruct
+func TestArrange(t *testing.T) {
+	t.Parallel()
+
+	day := func(d int) *time.Time {
+		t := time.Date(2022, 8, d, 12, 0, 0, 0, time.Local)
+
+		return &t
+	}
+
+	items := []*cache.Item{
+		{Item: &gofeed.Item{Title: "b", PublishedParsed: day(2)}, FeedURL: "A", Read: true},
+		{Item: &gofeed.Item{Title: "c", PublishedParsed: day(1)}, FeedURL: "A"},
+		{Item: &gofeed.Item{Title: "a", PublishedParsed: day(3)}, FeedURL: "B"},
+		{Item: &gofeed.Item{Title: "d"}, FeedURL: "B"},
+	}
+
+	tests := []struct {
+		view View
+		want string
+	}{
+		{View{Sort: SortFeed, Group: GroupNone}, "bcad"},
+		{View{Sort: SortNewest, Group: GroupNone}, "abcd"},
+		{View{Sort: SortOldest, Group: GroupNone}, "cbad"},
+		{View{Sort: SortTitle, Group: GroupNone}, "abcd"},
+		{View{Sort: SortUnread, Group: GroupNone}, "acdb"},
+		{View{Sort: SortNewest, Group: GroupFeed}, "bcad"},
+		{View{Sort: SortTitle, Group: GroupDay}, "abcd"},
+	}
+
+	for _, test := range tests {
+		test := test
+
+		have := ""
+		for _, item := range test.view.Arrange(items) {
+			have += item.Title
+		}
+
+		if have != test.want {
+			t.Errorf("%+v: got %s, want %s", test.view, have, test.want)
+		}
+	}
+}
@@ -2,18 +2,24 @@ package urlentry
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
 	"net/url"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
 
 	"github.com/kirsle/configdir"
+	"github.com/sheepla/srss/atomicfile"
 )
 
 //nolint:gochecknoglobals
 var urlFile = filepath.Join(configdir.LocalConfig(), "srss", "urls.txt")
 
+// commentPrefix starts the comment lines in the URL entry file.
+const commentPrefix = "#"
+
 func Add(url string) error {
 	if !isValidURL(url) {
 		//nolint:goerr113
@@ -48,6 +54,11 @@ func Load() ([]string, error) {
 	//nolint:gomnd,nosnakecase
 	file, err := os.OpenFile(urlFile, os.O_RDONLY, 0o666)
 	if err != nil {
+		// No URL has been registered yet.
+		if errors.Is(err, os.ErrNotExist) {
+			return []string{}, nil
+		}
+
 		return nil, fmt.Errorf("failed to open URL entry file (%s): %w", urlFile, err)
 	}
 	defer file.Close()
@@ -55,12 +66,16 @@ func Load() ([]string, error) {
 	scanner := bufio.NewScanner(file)
 	var urls []string
 	for scanner.Scan() {
-		url := scanner.Text()
+		url := strings.TrimSpace(scanner.Text())
+		// Blank lines and comments, e.g. the disabled feeds, are skipped.
+		if url == "" || strings.HasPrefix(url, commentPrefix) {
+			continue
+		}
 		if !isValidURL(url) {
 			//nolint:goerr113
 			return nil, fmt.Errorf("invalid URL(%s)", url)
 		}
-		urls = append(urls, scanner.Text())
+		urls = append(urls, url)
 	}
 	if scanner.Err() != nil {
 		return nil, fmt.Errorf("failed to scan from URL entry file (%s): %w", urlFile, err)
@@ -69,6 +84,60 @@ func Load() ([]string, error) {
 	return urls, nil
 }
 
+// Replace rewrites the URL in the URL entry file with the new one, e.g. when the feed moved permanently.
+func Replace(oldURL, newURL string) error {
+	if !isValidURL(newURL) {
+		//nolint:goerr113
+		return fmt.Errorf("invalid URL(%s)", newURL)
+	}
+
+	return rewrite(oldURL, func(string) string { return newURL })
+}
+
+// Disable comments out the URL in the URL entry file with the note, e.g. when the feed is gone.
+// The feed is enabled again by removing the comment prefix and the note.
+func Disable(url, note string) error {
+	return rewrite(url, func(line string) string {
+		return fmt.Sprintf("%s %s (%s)", commentPrefix, line, note)
+	})
+}
+
+// rewrite replaces the lines of the URL in the URL entry file with the result of replace.
+func rewrite(url string, replace func(line string) string) error {
+	data, err := os.ReadFile(urlFile)
+	if err != nil {
+		return fmt.Errorf("failed to read URL entry file (%s): %w", urlFile, err)
+	}
+
+	lines := strings.SplitAfter(string(data), "\n")
+	found := false
+
+	for i, line := range lines {
+		if strings.TrimSpace(line) != url {
+			continue
+		}
+
+		lines[i] = replace(url)
+		if strings.HasSuffix(line, "\n") {
+			lines[i] += "\n"
+		}
+
+		found = true
+	}
+
+	if !found {
+		//nolint:goerr113
+		return fmt.Errorf("the URL(%s) is not in the URL entry file", url)
+	}
+
+	//nolint:gomnd
+	if err := atomicfile.WriteFile(urlFile, []byte(strings.Join(lines, "")), 0o666); err != nil {
+		return fmt.Errorf("failed to write URL entry file: %w", err)
+	}
+
+	return nil
+}
+
 func IsUniqueURL(url string) bool {
 	list, err := Load()
 	if err != nil {
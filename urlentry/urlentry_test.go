@@ -0,0 +1,49 @@
+package urlentry
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+//nolint:paralleltest
+func TestReplaceAndDisable(t *testing.T) {
+	urlFile = filepath.Join(t.TempDir(), "urls.txt")
+
+	content := "https://example.com/old.xml\n\n# a comment\nhttps://example.com/gone.xml\nhttps://example.com/feed.xml\n"
+	if err := os.WriteFile(urlFile, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Replace("https://example.com/old.xml", "https://example.com/new.xml"); err != nil {
+		t.Fatalf("Replace() = %v", err)
+	}
+
+	if err := Disable("https://example.com/gone.xml", "410 Gone"); err != nil {
+		t.Fatalf("Disable() = %v", err)
+	}
+
+	if err := Replace("https://example.com/missing.xml", "https://example.com/new.xml"); err == nil {
+		t.Errorf("Replace() of a missing URL succeeded")
+	}
+
+	data, err := os.ReadFile(urlFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := "https://example.com/new.xml\n\n# a comment\n# https://example.com/gone.xml (410 Gone)\nhttps://example.com/feed.xml\n"
+	if string(data) != want {
+		t.Errorf("the URL entry file is\n%s\nwant\n%s", data, want)
+	}
+
+	urls, err := Load()
+	if err != nil {
+		t.Fatalf("Load() = %v", err)
+	}
+
+	if want := []string{"https://example.com/new.xml", "https://example.com/feed.xml"}; !reflect.DeepEqual(urls, want) {
+		t.Errorf("Load() = %q, want %q", urls, want)
+	}
+}